@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"math/rand"
+	"net/url"
+	"strings"
+	"time"
+
+	"crmapi/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const redacted = "REDACTED"
+
+// AccessLog writes one structured line per request. Failed requests are always
+// logged, successful ones according to the sample rate. Excluded paths are prefixes.
+func (h *handler) AccessLog() gin.HandlerFunc {
+	redact := make(map[string]bool, len(h.cfg.AccessLogRedactQuery))
+	for _, key := range h.cfg.AccessLogRedactQuery {
+		redact[strings.ToLower(strings.TrimSpace(key))] = true
+	}
+
+	return func(c *gin.Context) {
+		for _, prefix := range h.cfg.AccessLogExcludePaths {
+			if prefix != "" && strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < 400 && rand.Float64() >= h.cfg.AccessLogSampleRate {
+			return
+		}
+
+		auth := getAuthInfo(c)
+		fields := []logger.Field{
+			logger.String("method", c.Request.Method),
+			logger.String("route", c.FullPath()),
+			logger.String("path", c.Request.URL.Path),
+			logger.String("query", redactQuery(c.Request.URL.RawQuery, redact)),
+			logger.Int("status", status),
+			logger.Duration("latency", time.Since(start)),
+			logger.String("client_ip", c.ClientIP()),
+			logger.String("user_id", auth.UserID),
+			logger.String("user_role", auth.UserRole),
+			logger.String("request_id", requestID(c)),
+			logger.Int("response_size", c.Writer.Size()),
+		}
+
+		switch {
+		case status >= 500:
+			h.log.Error("access", fields...)
+		case status >= 400:
+			h.log.Warn("access", fields...)
+		default:
+			h.log.Info("access", fields...)
+		}
+	}
+}
+
+// redactQuery hides the values of sensitive query parameters such as tokens and codes
+func redactQuery(rawQuery string, redact map[string]bool) string {
+	if rawQuery == "" {
+		return ""
+	}
+
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return redacted
+	}
+
+	for key := range values {
+		if redact[strings.ToLower(key)] {
+			values[key] = []string{redacted}
+		}
+	}
+	return values.Encode()
+}
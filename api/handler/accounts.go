@@ -0,0 +1,293 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"crmapi/config"
+	ad "crmapi/genproto/user_service/administrators"
+	mn "crmapi/genproto/user_service/managers"
+	st "crmapi/genproto/user_service/students"
+	sp "crmapi/genproto/user_service/super_admins"
+	ss "crmapi/genproto/user_service/support_teachers"
+	ts "crmapi/genproto/user_service/teachers"
+)
+
+// phoneLookupLimit is the page size used to scan search hits for an exact phone match
+const phoneLookupLimit = 50
+
+var errAccountNotFound = errors.New("account not found")
+var errUnknownRole = errors.New("unknown user role")
+
+// account is the role independent view of a user record used by auth endpoints
+type account struct {
+	Id       string
+	Phone    string
+	Password string
+	BranchId string
+}
+
+func isKnownRole(role string) bool {
+	switch role {
+	case config.SUPER_ADMIN_TYPE, config.ADMIN_TYPE, config.MANAGER_TYPE,
+		config.TEACHER_TYPE, config.SUPPORT_TEACHER_TYPE, config.STUDENT_TYPE:
+		return true
+	}
+	return false
+}
+
+// findAccountByPhone searches the role's user_service client and returns the record with exactly this phone.
+// Search also matches parts of phones and names, so every page of hits is scanned.
+func (h *handler) findAccountByPhone(ctx context.Context, role, phone string) (*account, error) {
+	var search func(offset int64) ([]*account, int64, error)
+
+	switch role {
+	case config.SUPER_ADMIN_TYPE:
+		search = func(offset int64) ([]*account, int64, error) {
+			resp, err := h.grpcClient.SuperAdmins().GetAll(ctx, &sp.GetListSuperAdminRequest{Offset: offset, Limit: phoneLookupLimit, Search: phone})
+			if err != nil {
+				return nil, 0, err
+			}
+			accounts := []*account{}
+			for _, u := range resp.SuperAdmins {
+				accounts = append(accounts, &account{Id: u.Id, Phone: u.Phone, Password: u.Password})
+			}
+			return accounts, resp.Count, nil
+		}
+	case config.ADMIN_TYPE:
+		search = func(offset int64) ([]*account, int64, error) {
+			resp, err := h.grpcClient.Admins().GetAll(ctx, &ad.GetListAdminstratorsRequest{Offset: offset, Limit: phoneLookupLimit, Search: phone})
+			if err != nil {
+				return nil, 0, err
+			}
+			accounts := []*account{}
+			for _, u := range resp.Adminstrators {
+				accounts = append(accounts, &account{Id: u.Id, Phone: u.Phone, Password: u.Password, BranchId: u.BranchId})
+			}
+			return accounts, resp.Count, nil
+		}
+	case config.MANAGER_TYPE:
+		search = func(offset int64) ([]*account, int64, error) {
+			resp, err := h.grpcClient.Managers().GetAll(ctx, &mn.GetListManagersRequest{Offset: offset, Limit: phoneLookupLimit, Search: phone})
+			if err != nil {
+				return nil, 0, err
+			}
+			accounts := []*account{}
+			for _, u := range resp.Managers {
+				accounts = append(accounts, &account{Id: u.Id, Phone: u.Phone, Password: u.Password, BranchId: u.BranchId})
+			}
+			return accounts, resp.Count, nil
+		}
+	case config.TEACHER_TYPE:
+		search = func(offset int64) ([]*account, int64, error) {
+			resp, err := h.grpcClient.Teachers().GetAll(ctx, &ts.GetListTeachersRequest{Offset: offset, Limit: phoneLookupLimit, Search: phone})
+			if err != nil {
+				return nil, 0, err
+			}
+			accounts := []*account{}
+			for _, u := range resp.Teachers {
+				accounts = append(accounts, &account{Id: u.Id, Phone: u.Phone, Password: u.Password, BranchId: u.BranchId})
+			}
+			return accounts, resp.Count, nil
+		}
+	case config.SUPPORT_TEACHER_TYPE:
+		search = func(offset int64) ([]*account, int64, error) {
+			resp, err := h.grpcClient.SupportTeacher().GetAll(ctx, &ss.GetListSupportTeachersRequest{Offset: offset, Limit: phoneLookupLimit, Search: phone})
+			if err != nil {
+				return nil, 0, err
+			}
+			accounts := []*account{}
+			for _, u := range resp.SupportTeachers {
+				accounts = append(accounts, &account{Id: u.Id, Phone: u.Phone, Password: u.Password, BranchId: u.BranchId})
+			}
+			return accounts, resp.Count, nil
+		}
+	case config.STUDENT_TYPE:
+		search = func(offset int64) ([]*account, int64, error) {
+			resp, err := h.grpcClient.Students().GetAll(ctx, &st.GetListStudentsRequest{Offset: offset, Limit: phoneLookupLimit, Search: phone})
+			if err != nil {
+				return nil, 0, err
+			}
+			accounts := []*account{}
+			for _, u := range resp.Students {
+				accounts = append(accounts, &account{Id: u.Id, Phone: u.Phone, Password: u.Password, BranchId: u.BranchId})
+			}
+			return accounts, resp.Count, nil
+		}
+	default:
+		return nil, errUnknownRole
+	}
+
+	for offset := int64(0); ; offset += phoneLookupLimit {
+		accounts, total, err := search(offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range accounts {
+			if a.Phone == phone {
+				return a, nil
+			}
+		}
+
+		if len(accounts) == 0 || offset+int64(len(accounts)) >= total {
+			return nil, errAccountNotFound
+		}
+	}
+}
+
+// getAccount loads a user record of the given role by id
+func (h *handler) getAccount(ctx context.Context, role, id string) (*account, error) {
+	switch role {
+	case config.SUPER_ADMIN_TYPE:
+		u, err := h.grpcClient.SuperAdmins().GetById(ctx, &sp.SuperAdminPrimaryKey{Id: id})
+		if err != nil {
+			return nil, err
+		}
+		return &account{Id: u.Id, Phone: u.Phone, Password: u.Password}, nil
+	case config.ADMIN_TYPE:
+		u, err := h.grpcClient.Admins().GetById(ctx, &ad.AdminstratorPrimaryKey{Id: id})
+		if err != nil {
+			return nil, err
+		}
+		return &account{Id: u.Id, Phone: u.Phone, Password: u.Password, BranchId: u.BranchId}, nil
+	case config.MANAGER_TYPE:
+		u, err := h.grpcClient.Managers().GetById(ctx, &mn.ManagerPrimaryKey{Id: id})
+		if err != nil {
+			return nil, err
+		}
+		return &account{Id: u.Id, Phone: u.Phone, Password: u.Password, BranchId: u.BranchId}, nil
+	case config.TEACHER_TYPE:
+		u, err := h.grpcClient.Teachers().GetById(ctx, &ts.TeacherPrimaryKey{Id: id})
+		if err != nil {
+			return nil, err
+		}
+		return &account{Id: u.Id, Phone: u.Phone, Password: u.Password, BranchId: u.BranchId}, nil
+	case config.SUPPORT_TEACHER_TYPE:
+		u, err := h.grpcClient.SupportTeacher().GetById(ctx, &ss.SupportTeacherPrimaryKey{Id: id})
+		if err != nil {
+			return nil, err
+		}
+		return &account{Id: u.Id, Phone: u.Phone, Password: u.Password, BranchId: u.BranchId}, nil
+	case config.STUDENT_TYPE:
+		u, err := h.grpcClient.Students().GetById(ctx, &st.StudentPrimaryKey{Id: id})
+		if err != nil {
+			return nil, err
+		}
+		return &account{Id: u.Id, Phone: u.Phone, Password: u.Password, BranchId: u.BranchId}, nil
+	}
+
+	return nil, errUnknownRole
+}
+
+// setAccountPassword replaces only the password of a user record, all other fields are sent back unchanged
+func (h *handler) setAccountPassword(ctx context.Context, role, id, password string) error {
+	unlock := h.locks.lock(id)
+	defer unlock()
+
+	switch role {
+	case config.SUPER_ADMIN_TYPE:
+		u, err := h.grpcClient.SuperAdmins().GetById(ctx, &sp.SuperAdminPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+		_, err = h.grpcClient.SuperAdmins().Update(ctx, &sp.UpdateSuperAdmin{
+			Id:           u.Id,
+			FullName:     u.FullName,
+			Phone:        u.Phone,
+			Password:     password,
+			Salary:       u.Salary,
+			MonthsWorked: u.MonthsWorked,
+			IeltsScore:   u.IeltsScore,
+		})
+		return err
+	case config.ADMIN_TYPE:
+		u, err := h.grpcClient.Admins().GetById(ctx, &ad.AdminstratorPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+		_, err = h.grpcClient.Admins().Update(ctx, &ad.UpdateAdminstrator{
+			Id:           u.Id,
+			FullName:     u.FullName,
+			Phone:        u.Phone,
+			Password:     password,
+			Salary:       u.Salary,
+			MonthsWorked: u.MonthsWorked,
+			IeltsScore:   u.IeltsScore,
+			BranchId:     u.BranchId,
+		})
+		return err
+	case config.MANAGER_TYPE:
+		u, err := h.grpcClient.Managers().GetById(ctx, &mn.ManagerPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+		_, err = h.grpcClient.Managers().Update(ctx, &mn.UpdateManager{
+			Id:           u.Id,
+			FullName:     u.FullName,
+			Phone:        u.Phone,
+			Password:     password,
+			Salary:       u.Salary,
+			SuperAdminId: u.SuperAdminId,
+			BranchId:     u.BranchId,
+		})
+		return err
+	case config.TEACHER_TYPE:
+		u, err := h.grpcClient.Teachers().GetById(ctx, &ts.TeacherPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+		_, err = h.grpcClient.Teachers().Update(ctx, &ts.UpdateTeacher{
+			Id:                u.Id,
+			FullName:          u.FullName,
+			Phone:             u.Phone,
+			Password:          password,
+			Salary:            u.Salary,
+			MonthsWorked:      u.MonthsWorked,
+			IeltsScore:        u.IeltsScore,
+			IeltsAttepmsCount: u.IeltsAttepmsCount,
+			SupportTeacherId:  u.SupportTeacherId,
+			BranchId:          u.BranchId,
+			GroupId:           u.GroupId,
+		})
+		return err
+	case config.SUPPORT_TEACHER_TYPE:
+		u, err := h.grpcClient.SupportTeacher().GetById(ctx, &ss.SupportTeacherPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+		_, err = h.grpcClient.SupportTeacher().Update(ctx, &ss.UpdateSupportTeacher{
+			Id:                u.Id,
+			FullName:          u.FullName,
+			Phone:             u.Phone,
+			Password:          password,
+			IeltsScore:        u.IeltsScore,
+			IeltsAttepmsCount: u.IeltsAttepmsCount,
+			Salary:            u.Salary,
+			MonthsWorked:      u.MonthsWorked,
+			BranchId:          u.BranchId,
+			GroupId:           u.GroupId,
+		})
+		return err
+	case config.STUDENT_TYPE:
+		u, err := h.grpcClient.Students().GetById(ctx, &st.StudentPrimaryKey{Id: id})
+		if err != nil {
+			return err
+		}
+		_, err = h.grpcClient.Students().Update(ctx, &st.UpdateStudent{
+			Id:          u.Id,
+			FullName:    u.FullName,
+			Phone:       u.Phone,
+			Password:    password,
+			Scores:      u.Scores,
+			PaidSum:     u.PaidSum,
+			CourseCount: u.CourseCount,
+			TotalSum:    u.TotalSum,
+			BranchId:    u.BranchId,
+			GroupId:     u.GroupId,
+		})
+		return err
+	}
+
+	return errUnknownRole
+}
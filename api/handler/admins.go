@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"context"
 	"crmapi/genproto/user_service/administrators"
 	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateAdmin godoc
@@ -19,36 +21,30 @@ import (
 // @Produce  	json
 // @Param		admin body user_service.CreateAdminstrator true "admin"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateAdmin(c *gin.Context) {
 	admin := user_service.CreateAdminstrator{}
 
 	if err := c.ShouldBindJSON(&admin); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating an admin")
+		handleError(c, h.log, err, "error while creating an admin")
 		return
 	}
 
-	if err := pkg.ValidateFullName(admin.FullName); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "full name is not valid")
+	if err := pkg.Validate(&admin); err != nil {
+		handleError(c, h.log, err, "error while validating admin")
 		return
 	}
 
-	if err := pkg.ValidatePassword(admin.Password); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "password is not valid")
-		return
-	}
-
-	if err := pkg.ValidatePhone(admin.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "phone number starts with +998...")
+	if !h.checkBranch(c, admin.BranchId) {
 		return
 	}
 
 	resp, err := h.grpcClient.Admins().Create(c.Request.Context(), &admin)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating an admin")
+		handleError(c, h.log, err, "error while creating an admin")
 		return
 	}
 
@@ -64,15 +60,17 @@ func (h *handler) CreateAdmin(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,full_name,branch.name"
+// @Param		expand query string false "comma separated related records to embed: branch"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdAdmin(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating admin", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating admin", err.Error())
 		return
 	}
 
@@ -83,11 +81,21 @@ func (h *handler) GetByIdAdmin(c *gin.Context) {
 	resp, err := h.grpcClient.Admins().GetById(c.Request.Context(), &admin)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting admin", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting admin")
+		return
+	}
+
+	if !h.checkBranch(c, resp.BranchId) {
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
 
-	handleResponse(c, h.log, "Admin got successfully", http.StatusOK, resp)
+	setETag(c, resp)
+	handleResponse(c, h.log, "Admin got successfully", http.StatusOK, data)
 }
 
 // UpdateAdmin godoc
@@ -100,41 +108,70 @@ func (h *handler) GetByIdAdmin(c *gin.Context) {
 // @Produce  	json
 // @Param		admin body user_service.UpdateAdminstrator true "admin"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateAdmin(c *gin.Context) {
 	admin := user_service.UpdateAdminstrator{}
 
 	if err := c.ShouldBindJSON(&admin); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
 		return
 	}
 
-	if err := pkg.ValidateFullName(admin.FullName); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "full name is not valid")
+	if err := pkg.Validate(&admin); err != nil {
+		handleError(c, h.log, err, "error while validating admin")
 		return
 	}
 
-	if err := pkg.ValidatePassword(admin.Password); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "password is not valid")
-		return
-	}
+	unlock := h.locks.lock(admin.Id)
+	defer unlock()
 
-	if err := pkg.ValidatePhone(admin.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "phone number starts with +998...")
+	if !h.checkBranch(c, admin.BranchId) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Admins().GetById(c.Request.Context(), &user_service.AdminstratorPrimaryKey{Id: admin.Id})
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.Admins().Update(c.Request.Context(), &admin)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating an admin", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating an admin")
 		return
 	}
 	handleResponse(c, h.log, "Admin updated successfully", http.StatusOK, resp)
 }
 
+// PatchAdmin godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/admin/{id} [PATCH]
+// @Summary 	Partially update a admin
+// @Description API for changing only the given fields of a admin, fields left out keep their stored value, the password too. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		ADMINISTRATORS
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the admin"
+// @Param		admin body user_service.UpdateAdminstrator true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	502  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchAdmin(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "admin",
+		update: &user_service.UpdateAdminstrator{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Admins().GetById(ctx, &user_service.AdminstratorPrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.Admins().Update(ctx, update.(*user_service.UpdateAdminstrator))
+		},
+	})
+}
+
 // DeleteAdmin godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/admin/{id} [DELETE]
@@ -145,14 +182,14 @@ func (h *handler) UpdateAdmin(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteAdmin(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating admin id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating admin id", err.Error())
 		return
 	}
 
@@ -160,10 +197,16 @@ func (h *handler) DeleteAdmin(c *gin.Context) {
 		Id: id,
 	}
 
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Admins().GetById(c.Request.Context(), &admin)
+	}) {
+		return
+	}
+
 	_, err := h.grpcClient.Admins().Delete(c.Request.Context(), &admin)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting an admin", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting an admin")
 		return
 	}
 	
@@ -179,38 +222,53 @@ func (h *handler) DeleteAdmin(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	is_active query boolean false "true or false"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,full_name"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,full_name,branch.name"
+// @Param    	expand query string false "comma separated related records to embed: branch"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllAdmins(c *gin.Context) {
 	search := c.Query("search")
 
-	page, err := ParsePageQueryParam(c)
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
-		return
-	}
-	limit, err := ParseLimitQueryParam(c)
-
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
 
 	admins := user_service.GetListAdminstratorsRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
 		Search: search,
 	}
 
-	resp, err := h.grpcClient.Admins().GetAll(c.Request.Context(), &admins)
+	q, err := bindListQuery(c, &admins, &user_service.Adminstrator{})
+	if err != nil {
+		handleError(c, h.log, err, "error while parsing filters")
+		return
+	}
+
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		admins.Offset, admins.Limit = offset, limit
+		return h.grpcClient.Admins().GetAll(ctx, &admins)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all admins", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all admins")
 		return
 	}
-	handleResponse(c, h.log, "Admins got successfully", http.StatusOK, resp)
+
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	handleListResponse(c, h.log, "Admins got successfully", data, p)
 }
\ No newline at end of file
@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"context"
 	schedule_service "crmapi/genproto/schedule_service/attendances"
+	"crmapi/pkg"
+	"crmapi/pkg/metrics"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateAttendance godoc
@@ -18,24 +22,36 @@ import (
 // @Produce  	json
 // @Param		attendance body schedule_service.CreateAttendance true "attendance"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateAttendance(c *gin.Context) {
 	attendance := schedule_service.CreateAttendance{}
 
 	if err := c.ShouldBindJSON(&attendance); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a attendance")
+		handleError(c, h.log, err, "error while creating a attendance")
 		return
 	}
 
-	_, err := h.grpcClient.AttendancesService().Create(c.Request.Context(), &attendance)
+	if err := pkg.Validate(&attendance); err != nil {
+		handleError(c, h.log, err, "error while validating attendance")
+		return
+	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.lessonBranch(c.Request.Context(), attendance.LessonId)
+	}) {
+		return
+	}
+
+	resp, err := h.grpcClient.AttendancesService().Create(c.Request.Context(), &attendance)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a attendance")
+		handleError(c, h.log, err, "error while creating a attendance")
 		return
 	}
-	handleGrpcErrWithDescription(c, h.log, err, "Attendance created successfully")
+	metrics.AttendanceMarked.WithLabelValues(attendance.Status.String()).Inc()
+	handleResponse(c, h.log, "Attendance created successfully", http.StatusCreated, resp)
 }
 
 // GetByIdAttendance godoc
@@ -47,15 +63,16 @@ func (h *handler) CreateAttendance(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,status"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdAttendance(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating attendance id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating attendance id", err.Error())
 		return
 	}
 
@@ -66,11 +83,23 @@ func (h *handler) GetByIdAttendance(c *gin.Context) {
 	resp, err := h.grpcClient.AttendancesService().GetById(c.Request.Context(), &attendance)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting attendance", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting attendance")
+		return
+	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.lessonBranch(c.Request.Context(), resp.LessonId)
+	}) {
 		return
 	}
 
-	handleResponse(c, h.log, "Attendance got successfully", http.StatusOK, resp)
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	setETag(c, resp)
+	handleResponse(c, h.log, "Attendance got successfully", http.StatusOK, data)
 }
 
 // UpdateAttendance godoc
@@ -83,26 +112,74 @@ func (h *handler) GetByIdAttendance(c *gin.Context) {
 // @Produce  	json
 // @Param		attendance body schedule_service.UpdateAttendance true "attendance"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateAttendance(c *gin.Context) {
 	attendance := schedule_service.UpdateAttendance{}
 
 	if err := c.ShouldBindJSON(&attendance); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if err := pkg.Validate(&attendance); err != nil {
+		handleError(c, h.log, err, "error while validating attendance")
+		return
+	}
+
+	unlock := h.locks.lock(attendance.Id)
+	defer unlock()
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.lessonBranch(c.Request.Context(), attendance.LessonId)
+	}) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.storedAttendanceBranch(c.Request.Context(), attendance.Id)
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.AttendancesService().Update(c.Request.Context(), &attendance)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating a attendance", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating a attendance")
 		return
 	}
 	handleResponse(c, h.log, "attendance updated successfully", http.StatusOK, resp)
 }
 
+// PatchAttendance godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/attendance/{id} [PATCH]
+// @Summary 	Partially update a attendance
+// @Description API for changing only the given fields of a attendance, fields left out keep their stored value. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		ATTENDANCES
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the attendance"
+// @Param		attendance body schedule_service.UpdateAttendance true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchAttendance(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "attendance",
+		update: &schedule_service.UpdateAttendance{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.AttendancesService().GetById(ctx, &schedule_service.AttendancePrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.AttendancesService().Update(ctx, update.(*schedule_service.UpdateAttendance))
+		},
+		branch: func(ctx context.Context, record proto.Message) (branchRecord, error) {
+			return h.lessonBranch(ctx, record.(lessonRecord).GetLessonId())
+		},
+	})
+}
+
 // DeleteAttendance godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/attendance/{id} [DELETE]
@@ -113,24 +190,30 @@ func (h *handler) UpdateAttendance(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteAttendance(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating attendance id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating attendance id", err.Error())
 		return
 	}
 
 	attendance := schedule_service.AttendancePrimaryKey{
 		Id: id,
 	}
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.storedAttendanceBranch(c.Request.Context(), id)
+	}) {
+		return
+	}
+
 	_, err := h.grpcClient.AttendancesService().Delete(c.Request.Context(), &attendance)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting a attendance", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting a attendance")
 		return
 	}
 	handleResponse(c, h.log, "Attendance deleted successfully", http.StatusOK, "Attendance deleted successfully")
@@ -145,36 +228,48 @@ func (h *handler) DeleteAttendance(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	lesson_id query string false "lesson id"
+// @Param    	student_id query string false "student id"
+// @Param    	status query string false "attended, absent or late"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. status,-late_minute"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,status"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllAttendances(c *gin.Context) {
 
-	page, err := ParsePageQueryParam(c)
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
-	limit, err := ParseLimitQueryParam(c)
 
+	attendances := schedule_service.GetListAttendanceRequest{}
+
+	q, err := bindListQuery(c, &attendances, &schedule_service.Attendance{})
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing filters")
 		return
 	}
 
-	attendances := schedule_service.GetListAttendanceRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
-	}
-
-	resp, err := h.grpcClient.AttendancesService().GetAll(c.Request.Context(), &attendances)
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		attendances.Offset, attendances.Limit = offset, limit
+		return h.grpcClient.AttendancesService().GetAll(ctx, &attendances)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all attendances", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all attendances")
+		return
+	}
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
-	handleResponse(c, h.log, "Attendances got successfully", http.StatusOK, resp)
+
+	handleListResponse(c, h.log, "Attendances got successfully", data, p)
 }
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"crmapi/api/models"
+	"crmapi/pkg/i18n"
+	"crmapi/pkg/jwt"
+	"crmapi/pkg/metrics"
+	"crmapi/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ctxUserID   = "user_id"
+	ctxUserRole = "user_role"
+	ctxBranchID = "branch_id"
+)
+
+// authInfo describes the caller of an authenticated request
+type authInfo struct {
+	UserID   string
+	UserRole string
+	BranchID string
+}
+
+func getAuthInfo(c *gin.Context) authInfo {
+	return authInfo{
+		UserID:   c.GetString(ctxUserID),
+		UserRole: c.GetString(ctxUserRole),
+		BranchID: c.GetString(ctxBranchID),
+	}
+}
+
+// Login godoc
+// @Router 		/api/v1/auth/login [POST]
+// @Summary 	Login
+// @Description API for logging in with phone and password, user_role is one of super_admin, admin, manager, teacher, support_teacher, student, the optional language (uz, ru, en) is kept in the tokens and takes precedence over Accept-Language
+// @Tags 		AUTH
+// @Accept  	json
+// @Produce  	json
+// @Param		login body models.LoginRequest true "login"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	401  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) Login(c *gin.Context) {
+	req := models.LoginRequest{}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if req.Phone == "" || req.Password == "" {
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorBadRequest, "phone and password are required", nil)
+		return
+	}
+
+	if !isKnownRole(req.UserRole) {
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorBadRequest, "error while validating user role", errUnknownRole.Error())
+		return
+	}
+
+	if req.Language != "" && !i18n.Supported(req.Language) {
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorBadRequest, "error while validating language", "language must be one of uz, ru, en")
+		return
+	}
+
+	user, err := h.findAccountByPhone(c.Request.Context(), req.UserRole, req.Phone)
+	if errors.Is(err, errAccountNotFound) {
+		metrics.Logins.WithLabelValues(req.UserRole, "failure").Inc()
+		handleProblem(c, h.log, http.StatusUnauthorized, ErrorCodeUnauthorized, "login failed, unknown phone", "invalid phone or password")
+		return
+	}
+	if err != nil {
+		handleError(c, h.log, err, "error while finding user by phone")
+		return
+	}
+
+	if !security.CompareHashAndPassword(user.Password, req.Password) {
+		metrics.Logins.WithLabelValues(req.UserRole, "failure").Inc()
+		handleProblem(c, h.log, http.StatusUnauthorized, ErrorCodeUnauthorized, "login failed, wrong password", "invalid phone or password")
+		return
+	}
+
+	resp, err := h.issueTokens(req.UserRole, user, req.Language)
+	if err != nil {
+		handleProblem(c, h.log, http.StatusInternalServerError, ErrorCodeInternal, "error while generating tokens: "+err.Error(), "error while generating tokens")
+		return
+	}
+
+	metrics.Logins.WithLabelValues(req.UserRole, "success").Inc()
+	handleResponse(c, h.log, "Logged in successfully", http.StatusOK, resp)
+}
+
+// RefreshToken godoc
+// @Router 		/api/v1/auth/refresh [POST]
+// @Summary 	Refresh tokens
+// @Description API for exchanging a refresh token for a new token pair
+// @Tags 		AUTH
+// @Accept  	json
+// @Produce  	json
+// @Param		refresh body models.RefreshTokenRequest true "refresh"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	401  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) RefreshToken(c *gin.Context) {
+	req := models.RefreshTokenRequest{}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	claims, err := jwt.ExtractClaims(req.RefreshToken, h.cfg.SecretKey)
+	if err != nil || claims.TokenType != jwt.RefreshToken {
+		handleProblem(c, h.log, http.StatusUnauthorized, ErrorCodeUnauthorized, "invalid refresh token", nil)
+		return
+	}
+
+	user, err := h.getAccount(c.Request.Context(), claims.UserRole, claims.UserID)
+	if err != nil {
+		handleError(c, h.log, err, "error while getting user for refresh")
+		return
+	}
+
+	resp, err := h.issueTokens(claims.UserRole, user, claims.Language)
+	if err != nil {
+		handleProblem(c, h.log, http.StatusInternalServerError, ErrorCodeInternal, "error while generating tokens: "+err.Error(), "error while generating tokens")
+		return
+	}
+
+	handleResponse(c, h.log, "Tokens refreshed successfully", http.StatusOK, resp)
+}
+
+func (h *handler) issueTokens(role string, user *account, lang string) (models.LoginResponse, error) {
+	claims := jwt.Claims{
+		UserID:   user.Id,
+		UserRole: role,
+		BranchID: user.BranchId,
+		Language: lang,
+	}
+
+	claims.TokenType = jwt.AccessToken
+	accessToken, err := jwt.GenerateJWT(claims, h.cfg.AccessTokenTTL, h.cfg.SecretKey)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	claims.TokenType = jwt.RefreshToken
+	refreshToken, err := jwt.GenerateJWT(claims, h.cfg.RefreshTokenTTL, h.cfg.SecretKey)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	return models.LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		UserID:       user.Id,
+		UserRole:     role,
+		Language:     lang,
+	}, nil
+}
+
+// AuthMiddleware rejects requests without a valid access token in the Authorization header
+func (h *handler) AuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimSpace(strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer "))
+		if token == "" {
+			handleProblem(c, h.log, http.StatusUnauthorized, ErrorCodeUnauthorized, "authorization header is missing", nil)
+			return
+		}
+
+		claims, err := jwt.ExtractClaims(token, h.cfg.SecretKey)
+		if err != nil || claims.TokenType != jwt.AccessToken {
+			handleProblem(c, h.log, http.StatusUnauthorized, ErrorCodeUnauthorized, "invalid access token", nil)
+			return
+		}
+
+		c.Set(ctxUserID, claims.UserID)
+		c.Set(ctxUserRole, claims.UserRole)
+		c.Set(ctxBranchID, claims.BranchID)
+		c.Set(ctxLanguage, claims.Language)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"crmapi/config"
+	at "crmapi/genproto/schedule_service/attendances"
+	ls "crmapi/genproto/schedule_service/lessons"
+	sc "crmapi/genproto/schedule_service/schedules"
+	tk "crmapi/genproto/schedule_service/tasks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errOtherBranch is returned when a branch scoped caller asks for another branch
+var errOtherBranch = errors.New("record belongs to another branch")
+
+// branchRecord is implemented by every generated message that carries a branch_id
+type branchRecord interface {
+	GetBranchId() string
+}
+
+// scheduleRecord and lessonRecord are implemented by schedule_service messages that carry
+// no branch_id of their own and belong to the branch of their schedule or lesson
+type scheduleRecord interface {
+	GetScheduleId() string
+}
+
+type lessonRecord interface {
+	GetLessonId() string
+}
+
+// branchScope returns the caller's branch when the caller may only work inside one branch.
+// Super admins are not scoped.
+func branchScope(c *gin.Context) (string, bool) {
+	auth := getAuthInfo(c)
+	if auth.UserRole == config.SUPER_ADMIN_TYPE {
+		return "", false
+	}
+
+	return auth.BranchID, true
+}
+
+// checkBranch writes ErrorCodeWrongClub and returns false when branchID is not the caller's branch
+func (h *handler) checkBranch(c *gin.Context, branchID string) bool {
+	scope, scoped := branchScope(c)
+	if !scoped || (scope != "" && scope == branchID) {
+		return true
+	}
+
+	handleProblem(c, h.log, http.StatusForbidden, ErrorCodeWrongClub, "record belongs to another branch", nil)
+	return false
+}
+
+// checkRecordBranch loads the stored record and checks that it belongs to the caller's branch,
+// so that records cannot be moved out of or deleted from another branch
+func (h *handler) checkRecordBranch(c *gin.Context, get func() (branchRecord, error)) bool {
+	if _, scoped := branchScope(c); !scoped {
+		return true
+	}
+
+	record, err := get()
+	if err != nil {
+		handleError(c, h.log, err, "error while checking record branch")
+		return false
+	}
+
+	return h.checkBranch(c, record.GetBranchId())
+}
+
+// scheduleBranch returns the schedule, whose branch is the branch of its lessons
+func (h *handler) scheduleBranch(ctx context.Context, scheduleID string) (branchRecord, error) {
+	schedule, err := h.grpcClient.Schedules().GetById(ctx, &sc.SchedulePrimaryKey{Id: scheduleID})
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// lessonBranch returns the schedule of a lesson, its branch is the branch of the lesson's tasks and attendances
+func (h *handler) lessonBranch(ctx context.Context, lessonID string) (branchRecord, error) {
+	lesson, err := h.grpcClient.LessonsService().GetById(ctx, &ls.LessonPrimaryKey{Id: lessonID})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.scheduleBranch(ctx, lesson.ScheduleId)
+}
+
+// storedTaskBranch and storedAttendanceBranch resolve the branch of a stored record by its id
+func (h *handler) storedTaskBranch(ctx context.Context, id string) (branchRecord, error) {
+	task, err := h.grpcClient.Tasks().GetById(ctx, &tk.TaskPrimaryKey{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.lessonBranch(ctx, task.LessonId)
+}
+
+func (h *handler) storedAttendanceBranch(ctx context.Context, id string) (branchRecord, error) {
+	attendance, err := h.grpcClient.AttendancesService().GetById(ctx, &at.AttendancePrimaryKey{Id: id})
+	if err != nil {
+		return nil, err
+	}
+
+	return h.lessonBranch(ctx, attendance.LessonId)
+}
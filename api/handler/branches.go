@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"crmapi/genproto/user_service/branches"
+	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateBranch godoc
@@ -18,21 +21,26 @@ import (
 // @Produce  	json
 // @Param		super-admin body user_service.CreateBranch true "branch"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateBranch(c *gin.Context) {
 	branch := user_service.CreateBranch{}
 
 	if err := c.ShouldBindJSON(&branch); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a branch")
+		handleError(c, h.log, err, "error while creating a branch")
+		return
+	}
+
+	if err := pkg.Validate(&branch); err != nil {
+		handleError(c, h.log, err, "error while validating branch")
 		return
 	}
 
 	resp, err := h.grpcClient.Branches().Create(c.Request.Context(), &branch)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a branch")
+		handleError(c, h.log, err, "error while creating a branch")
 		return
 	}
 
@@ -48,15 +56,16 @@ func (h *handler) CreateBranch(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,name"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdBranch(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating branch", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating branch", err.Error())
 		return
 	}
 
@@ -67,11 +76,17 @@ func (h *handler) GetByIdBranch(c *gin.Context) {
 	resp, err := h.grpcClient.Branches().GetById(c.Request.Context(), &branch)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting branch", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting branch")
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
 
-	handleResponse(c, h.log, "Branch got successfully", http.StatusOK, resp)
+	setETag(c, resp)
+	handleResponse(c, h.log, "Branch got successfully", http.StatusOK, data)
 }
 
 // UpdateBranch godoc
@@ -84,26 +99,63 @@ func (h *handler) GetByIdBranch(c *gin.Context) {
 // @Produce  	json
 // @Param		branch body user_service.UpdateBranch true "branch"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateBranch(c *gin.Context) {
 	branch := user_service.UpdateBranch{}
 
 	if err := c.ShouldBindJSON(&branch); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
 		return
 	}
 
+	if err := pkg.Validate(&branch); err != nil {
+		handleError(c, h.log, err, "error while validating branch")
+		return
+	}
+
+	unlock := h.locks.lock(branch.Id)
+	defer unlock()
+
 	resp, err := h.grpcClient.Branches().Update(c.Request.Context(), &branch)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating a branch", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating a branch")
 		return
 	}
 	handleResponse(c, h.log, "Branch updated successfully", http.StatusOK, resp)
 }
 
+// PatchBranch godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/branch/{id} [PATCH]
+// @Summary 	Partially update a branch
+// @Description API for changing only the given fields of a branch, fields left out keep their stored value. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		BRANCHES
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the branch"
+// @Param		branch body user_service.UpdateBranch true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchBranch(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "branch",
+		update: &user_service.UpdateBranch{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Branches().GetById(ctx, &user_service.BranchePrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.Branches().Update(ctx, update.(*user_service.UpdateBranch))
+		},
+	})
+}
+
 // DeleteBranch godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/branch/{id} [DELETE]
@@ -114,14 +166,14 @@ func (h *handler) UpdateBranch(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteBranch(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating branch id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating branch id", err.Error())
 		return
 	}
 
@@ -132,7 +184,7 @@ func (h *handler) DeleteBranch(c *gin.Context) {
 	_, err := h.grpcClient.Branches().Delete(c.Request.Context(), &branch)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting an branch", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting an branch")
 		return
 	}
 	handleResponse(c, h.log, "Branch deleted successfully", http.StatusOK, "Branch deleted successfully")
@@ -147,39 +199,51 @@ func (h *handler) DeleteBranch(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	is_active query boolean false "true or false"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,id"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,name"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllBranches(c *gin.Context) {
 	search := c.Query("search")
 
-	page, err := ParsePageQueryParam(c)
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
-		return
-	}
-	limit, err := ParseLimitQueryParam(c)
-
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
 
 	branches := user_service.GetListBranchesRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
 		Search: search,
 	}
 
-	resp, err := h.grpcClient.Branches().GetAll(c.Request.Context(), &branches)
+	q, err := bindListQuery(c, &branches, &user_service.Branch{})
+	if err != nil {
+		handleError(c, h.log, err, "error while parsing filters")
+		return
+	}
+
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		branches.Offset, branches.Limit = offset, limit
+		return h.grpcClient.Branches().GetAll(ctx, &branches)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all branches", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all branches")
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
 
-	handleResponse(c, h.log, "Branches got successfully", http.StatusOK, resp)
+	handleListResponse(c, h.log, "Branches got successfully", data, p)
 }
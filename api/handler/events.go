@@ -1,13 +1,14 @@
 package handler
 
 import (
+	"context"
 	user_service "crmapi/genproto/user_service/events"
 	"crmapi/pkg"
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateEvent godoc
@@ -20,26 +21,30 @@ import (
 // @Produce  	json
 // @Param		event body user_service.CreateEvent true "event"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateEvent(c *gin.Context) {
 	event := user_service.CreateEvent{}
 
 	if err := c.ShouldBindJSON(&event); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating an event")
+		handleError(c, h.log, err, "error while creating an event")
 		return
 	}
 
-	if pkg.ValidateDay(event.Day) {
-		handleGrpcErrWithDescription(c, h.log, errors.New("must be sunday"), "error while creating an event")
+	if err := pkg.Validate(&event); err != nil {
+		handleError(c, h.log, err, "error while validating event")
+		return
+	}
+
+	if !h.checkBranch(c, event.BranchId) {
 		return
 	}
 
 	resp, err := h.grpcClient.Events().Create(c.Request.Context(), &event)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating an event")
+		handleError(c, h.log, err, "error while creating an event")
 		return
 	}
 
@@ -55,15 +60,17 @@ func (h *handler) CreateEvent(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,topic,branch.name"
+// @Param		expand query string false "comma separated related records to embed: branch"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdEvent(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating admin", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating admin", err.Error())
 		return
 	}
 
@@ -74,11 +81,21 @@ func (h *handler) GetByIdEvent(c *gin.Context) {
 	resp, err := h.grpcClient.Events().GetById(c.Request.Context(), &event)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting event", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting event")
+		return
+	}
+
+	if !h.checkBranch(c, resp.BranchId) {
 		return
 	}
 
-	handleResponse(c, h.log, "Event got successfully", http.StatusOK, resp)
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	setETag(c, resp)
+	handleResponse(c, h.log, "Event got successfully", http.StatusOK, data)
 }
 
 // UpdateEvent godoc
@@ -91,26 +108,69 @@ func (h *handler) GetByIdEvent(c *gin.Context) {
 // @Produce  	json
 // @Param		admin body user_service.UpdateEvent true "admin"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateEvent(c *gin.Context) {
 	event := user_service.UpdateEvent{}
 
 	if err := c.ShouldBindJSON(&event); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if err := pkg.Validate(&event); err != nil {
+		handleError(c, h.log, err, "error while validating event")
+		return
+	}
+
+	unlock := h.locks.lock(event.Id)
+	defer unlock()
+
+	if !h.checkBranch(c, event.BranchId) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Events().GetById(c.Request.Context(), &user_service.EventPrimaryKey{Id: event.Id})
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.Events().Update(c.Request.Context(), &event)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating an event", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating an event")
 		return
 	}
 	handleResponse(c, h.log, "Event updated successfully", http.StatusOK, resp)
 }
 
+// PatchEvent godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/event/{id} [PATCH]
+// @Summary 	Partially update a event
+// @Description API for changing only the given fields of a event, fields left out keep their stored value. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		EVENTS
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the event"
+// @Param		event body user_service.UpdateEvent true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchEvent(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "event",
+		update: &user_service.UpdateEvent{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Events().GetById(ctx, &user_service.EventPrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.Events().Update(ctx, update.(*user_service.UpdateEvent))
+		},
+	})
+}
+
 // DeleteEvent godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/event/{id} [DELETE]
@@ -121,14 +181,14 @@ func (h *handler) UpdateEvent(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteEvent(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating event id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating event id", err.Error())
 		return
 	}
 
@@ -136,10 +196,16 @@ func (h *handler) DeleteEvent(c *gin.Context) {
 		Id: id,
 	}
 
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Events().GetById(c.Request.Context(), &event)
+	}) {
+		return
+	}
+
 	_, err := h.grpcClient.Events().Delete(c.Request.Context(), &event)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting an event", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting an event")
 		return
 	}
 	handleResponse(c, h.log, "Event deleted successfully", http.StatusOK, "Event deleted successfully")
@@ -154,38 +220,53 @@ func (h *handler) DeleteEvent(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	is_active query boolean false "true or false"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,id"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,topic,branch.name"
+// @Param    	expand query string false "comma separated related records to embed: branch"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllEvents(c *gin.Context) {
 	search := c.Query("search")
 
-	page, err := ParsePageQueryParam(c)
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
-		return
-	}
-	limit, err := ParseLimitQueryParam(c)
-
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
 
 	events := user_service.GetListEventsRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
 		Search: search,
 	}
 
-	resp, err := h.grpcClient.Events().GetAll(c.Request.Context(), &events)
+	q, err := bindListQuery(c, &events, &user_service.Event{})
+	if err != nil {
+		handleError(c, h.log, err, "error while parsing filters")
+		return
+	}
+
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		events.Offset, events.Limit = offset, limit
+		return h.grpcClient.Events().GetAll(ctx, &events)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all events", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all events")
 		return
 	}
-	handleResponse(c, h.log, "Events got successfully", http.StatusOK, resp)
+
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	handleListResponse(c, h.log, "Events got successfully", data, p)
 }
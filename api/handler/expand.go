@@ -0,0 +1,378 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	br "crmapi/genproto/user_service/branches"
+	gr "crmapi/genproto/user_service/groups"
+	ss "crmapi/genproto/user_service/support_teachers"
+	ts "crmapi/genproto/user_service/teachers"
+	"crmapi/pkg"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	fieldsParam = "fields"
+	expandParam = "expand"
+
+	// expandConcurrency limits the backend calls one response may run at the same time
+	expandConcurrency = 8
+)
+
+// relation is a record that can be embedded with ?expand=<name>. It is resolved from
+// the <name>_id field of the response records through the GetById of its service.
+type relation struct {
+	route  string // GET route whose rbac rule decides who may expand it
+	record proto.Message
+	get    func(h *handler, ctx context.Context, id string) (proto.Message, error)
+}
+
+var relations = map[string]relation{
+	"branch": {
+		route:  "/api/v1/branch/:id",
+		record: &br.Branch{},
+		get: func(h *handler, ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Branches().GetById(ctx, &br.BranchePrimaryKey{Id: id})
+		},
+	},
+	"group": {
+		route:  "/api/v1/group/:id",
+		record: &gr.Group{},
+		get: func(h *handler, ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Groups().GetById(ctx, &gr.GroupPrimaryKey{Id: id})
+		},
+	},
+	"teacher": {
+		route:  "/api/v1/teacher/:id",
+		record: &ts.Teacher{},
+		get: func(h *handler, ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Teachers().GetById(ctx, &ts.TeacherPrimaryKey{Id: id})
+		},
+	},
+	"support_teacher": {
+		route:  "/api/v1/support-teacher/:id",
+		record: &ss.SupportTeacher{},
+		get: func(h *handler, ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.SupportTeacher().GetById(ctx, &ss.SupportTeacherPrimaryKey{Id: id})
+		},
+	},
+}
+
+// shape applies ?expand= and ?fields= to a GetById or GetAll response. Expanded records are
+// embedded next to their <name>_id, e.g. "group": {...}, or null when they are gone or out of
+// the caller's branch. ?fields= keeps only the listed fields of every record, expanded records
+// are always kept and can be trimmed with dotted names like group.name. With either param the
+// envelope of a list, its count and records, is never trimmed and always present, also for an
+// empty page. Without them the response is returned as is. On failure the problem is written
+// and false is returned.
+func (h *handler) shape(c *gin.Context, resp proto.Message) (interface{}, bool) {
+	expand, fields := splitParam(c.Query(expandParam)), splitParam(c.Query(fieldsParam))
+	if len(expand) == 0 && len(fields) == 0 {
+		return resp, true
+	}
+	listField, record := records(resp)
+
+	if err := validateShape(record, expand, fields); err != nil {
+		handleError(c, h.log, err, "error while parsing fields")
+		return nil, false
+	}
+	for _, name := range expand {
+		if !h.mayExpand(c, name) {
+			handleProblem(c, h.log, http.StatusForbidden, ErrorCodeForbidden, "role is not allowed to expand "+name, nil)
+			return nil, false
+		}
+	}
+
+	data, err := toGeneric(resp)
+	if err != nil {
+		handleError(c, h.log, err, "error while shaping response")
+		return nil, false
+	}
+
+	items := []map[string]interface{}{}
+	if root, ok := data.(map[string]interface{}); ok {
+		if listField == "" {
+			items = append(items, root)
+		} else {
+			fillEnvelope(root, resp)
+			list, _ := root[listField].([]interface{})
+			for _, item := range list {
+				if m, ok := item.(map[string]interface{}); ok {
+					items = append(items, m)
+				}
+			}
+		}
+	}
+
+	if len(expand) > 0 {
+		related, err := h.fetchRelated(c, expand, items)
+		if err != nil {
+			handleError(c, h.log, err, "error while expanding related records")
+			return nil, false
+		}
+
+		for _, item := range items {
+			for _, name := range expand {
+				id, _ := item[name+"_id"].(string)
+				item[name] = related[name][id]
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		selected := selectFields(fields)
+		for _, name := range expand {
+			if _, ok := selected[name]; !ok {
+				selected[name] = nil
+			}
+		}
+		for _, item := range items {
+			trimFields(item, selected)
+		}
+	}
+
+	return data, true
+}
+
+// records returns the repeated field holding the items of a list response and an empty
+// item, or "" and the response itself when it is a single record
+func records(resp proto.Message) (string, protoreflect.Message) {
+	fields := resp.ProtoReflect().Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.IsList() && fd.Message() != nil {
+			return string(fd.Name()), resp.ProtoReflect().NewField(fd).List().NewElement().Message()
+		}
+	}
+	return "", resp.ProtoReflect()
+}
+
+// fillEnvelope adds the fields of a list response left out of its json because they are
+// empty, so a page always has its count and an array of records
+func fillEnvelope(root map[string]interface{}, resp proto.Message) {
+	msg := resp.ProtoReflect()
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if _, ok := root[string(fd.Name())]; ok {
+			continue
+		}
+		switch {
+		case fd.IsList():
+			root[string(fd.Name())] = []interface{}{}
+		case fd.Message() == nil:
+			root[string(fd.Name())] = msg.Get(fd).Interface()
+		}
+	}
+}
+
+// validateShape checks the expanded relations and the selected fields against the record
+func validateShape(record protoreflect.Message, expand, fields []string) error {
+	var errs pkg.ValidationErrors
+
+	own := record.Descriptor().Fields()
+	expanded := map[string]protoreflect.FieldDescriptors{}
+
+	allowed := []string{}
+	for name := range relations {
+		if own.ByName(protoreflect.Name(name+"_id")) != nil {
+			allowed = append(allowed, name)
+		}
+	}
+	sort.Strings(allowed)
+
+	invalid := false
+	for _, name := range expand {
+		if rel, ok := relations[name]; ok && own.ByName(protoreflect.Name(name+"_id")) != nil {
+			expanded[name] = rel.record.ProtoReflect().Descriptor().Fields()
+		} else {
+			invalid = true
+		}
+	}
+	if invalid {
+		msg, key, args := "cannot be expanded on this resource", "", []interface{}(nil)
+		if len(allowed) > 0 {
+			msg, key, args = "must be one of "+strings.Join(allowed, ", "), "enum_values", []interface{}{strings.Join(allowed, ", ")}
+		}
+		errs = append(errs, pkg.FieldError{Field: expandParam, Rule: "enum", Message: msg, Key: key, Args: args})
+	}
+
+	for _, field := range fields {
+		parent, child, nested := strings.Cut(field, ".")
+
+		known := own.ByName(protoreflect.Name(field)) != nil
+		if related, ok := expanded[parent]; ok {
+			known = !nested || related.ByName(protoreflect.Name(child)) != nil
+		}
+		if !known {
+			errs = append(errs, pkg.FieldError{Field: fieldsParam, Rule: "unknown", Message: field + " is not a field of this resource", Key: "unknown_field", Args: []interface{}{field}})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// mayExpand reports whether the caller's role may read the records of a relation
+func (h *handler) mayExpand(c *gin.Context, name string) bool {
+	if h.policy == nil {
+		return true
+	}
+	decision := h.policy.Check(http.MethodGet, relations[name].route, getAuthInfo(c).UserRole)
+	return decision.Allowed && !decision.SelfOnly
+}
+
+// fetchRelated loads every distinct related id of the items with one GetById each, the
+// backends have no lookup of many ids. The calls of all relations run at the same time,
+// at most expandConcurrency at once, and the records are returned by relation and id in
+// their json form. Records that do not exist or belong to another branch than the
+// caller's are left out. A page holds at most MaxPageSize records, so a response makes at
+// most MaxPageSize calls per relation.
+func (h *handler) fetchRelated(c *gin.Context, expand []string, items []map[string]interface{}) (map[string]map[string]interface{}, error) {
+	type job struct{ name, id string }
+
+	related := map[string]map[string]interface{}{}
+	jobs := []job{}
+	for _, name := range expand {
+		related[name] = map[string]interface{}{}
+		seen := map[string]bool{}
+		for _, item := range items {
+			id, _ := item[name+"_id"].(string)
+			if id != "" && !seen[id] {
+				seen[id] = true
+				jobs = append(jobs, job{name, id})
+			}
+		}
+	}
+	if most := len(expand) * h.cfg.MaxPageSize; len(jobs) > most {
+		return nil, pkg.ValidationErrors{{Field: expandParam, Rule: "max", Message: fmt.Sprintf("can embed at most %d related records, ask for a smaller page", most), Key: "expand_max", Args: []interface{}{most}}}
+	}
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	scope, scoped := branchScope(c)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		limit    = make(chan struct{}, expandConcurrency)
+	)
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j job) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			record, err := relations[j.name].get(h, ctx, j.id)
+			if status.Code(err) == codes.NotFound {
+				return
+			}
+
+			var value interface{}
+			if err == nil && (!scoped || inBranch(j.name, record, scope)) {
+				value, err = toGeneric(record)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			if value != nil {
+				related[j.name][j.id] = value
+			}
+		}(j)
+	}
+	wg.Wait()
+
+	return related, firstErr
+}
+
+// inBranch reports whether a related record belongs to the branch of a scoped caller
+func inBranch(name string, record proto.Message, branchID string) bool {
+	if name == "branch" {
+		return branchID != "" && record.(*br.Branch).GetId() == branchID
+	}
+	if r, ok := record.(branchRecord); ok {
+		return branchID != "" && r.GetBranchId() == branchID
+	}
+	return true
+}
+
+// selectFields turns fields like full_name,group.name into the fields kept per level,
+// a nil entry keeps the whole value
+func selectFields(fields []string) map[string][]string {
+	selected := map[string][]string{}
+	for _, field := range fields {
+		parent, child, nested := strings.Cut(field, ".")
+		switch kept, ok := selected[parent]; {
+		case !nested:
+			selected[parent] = nil
+		case !ok || kept != nil:
+			selected[parent] = append(kept, child)
+		}
+	}
+	return selected
+}
+
+func trimFields(item map[string]interface{}, selected map[string][]string) {
+	for key, value := range item {
+		children, ok := selected[key]
+		if !ok {
+			delete(item, key)
+			continue
+		}
+		if nested, isMap := value.(map[string]interface{}); isMap && children != nil {
+			keep := map[string][]string{}
+			for _, child := range children {
+				keep[child] = nil
+			}
+			trimFields(nested, keep)
+		}
+	}
+}
+
+// toGeneric converts a value to maps and slices the way it is serialized in responses
+func toGeneric(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	err = json.Unmarshal(raw, &generic)
+	return generic, err
+}
+
+// splitParam splits a comma separated query param and drops duplicates and empty entries
+func splitParam(raw string) []string {
+	out := []string{}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		if part = strings.TrimSpace(part); part != "" && !seen[part] {
+			seen[part] = true
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	user_service "crmapi/genproto/user_service/groups"
+	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateGroup godoc
@@ -18,21 +21,30 @@ import (
 // @Produce  	json
 // @Param		group body user_service.CreateGroup true "group"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateGroup(c *gin.Context) {
 	group := user_service.CreateGroup{}
 
 	if err := c.ShouldBindJSON(&group); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a group")
+		handleError(c, h.log, err, "error while creating a group")
+		return
+	}
+
+	if err := pkg.Validate(&group); err != nil {
+		handleError(c, h.log, err, "error while validating group")
+		return
+	}
+
+	if !h.checkBranch(c, group.BranchId) {
 		return
 	}
 
 	resp, err := h.grpcClient.Groups().Create(c.Request.Context(), &group)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a group")
+		handleError(c, h.log, err, "error while creating a group")
 		return
 	}
 	handleResponse(c, h.log, "Created group", http.StatusCreated, resp)
@@ -47,15 +59,17 @@ func (h *handler) CreateGroup(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,name,branch.name"
+// @Param		expand query string false "comma separated related records to embed: branch"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdGroup(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating group id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating group id", err.Error())
 		return
 	}
 
@@ -66,11 +80,21 @@ func (h *handler) GetByIdGroup(c *gin.Context) {
 	resp, err := h.grpcClient.Groups().GetById(c.Request.Context(), &group)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting group", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting group")
+		return
+	}
+
+	if !h.checkBranch(c, resp.BranchId) {
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
 
-	handleResponse(c, h.log, "Group got successfully", http.StatusOK, resp)
+	setETag(c, resp)
+	handleResponse(c, h.log, "Group got successfully", http.StatusOK, data)
 }
 
 // UpdateGroup godoc
@@ -83,26 +107,69 @@ func (h *handler) GetByIdGroup(c *gin.Context) {
 // @Produce  	json
 // @Param		group body user_service.UpdateGroup true "group"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateGroup(c *gin.Context) {
 	group := user_service.UpdateGroup{}
 
 	if err := c.ShouldBindJSON(&group); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if err := pkg.Validate(&group); err != nil {
+		handleError(c, h.log, err, "error while validating group")
+		return
+	}
+
+	unlock := h.locks.lock(group.Id)
+	defer unlock()
+
+	if !h.checkBranch(c, group.BranchId) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Groups().GetById(c.Request.Context(), &user_service.GroupPrimaryKey{Id: group.Id})
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.Groups().Update(c.Request.Context(), &group)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating a group", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating a group")
 		return
 	}
 	handleResponse(c, h.log, "Group updated successfully", http.StatusOK, resp)
 }
 
+// PatchGroup godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/group/{id} [PATCH]
+// @Summary 	Partially update a group
+// @Description API for changing only the given fields of a group, fields left out keep their stored value. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		GROUPS
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the group"
+// @Param		group body user_service.UpdateGroup true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchGroup(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "group",
+		update: &user_service.UpdateGroup{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Groups().GetById(ctx, &user_service.GroupPrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.Groups().Update(ctx, update.(*user_service.UpdateGroup))
+		},
+	})
+}
+
 // DeleteGroup godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/group/{id} [DELETE]
@@ -113,24 +180,31 @@ func (h *handler) UpdateGroup(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteGroup(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating group id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating group id", err.Error())
 		return
 	}
 
 	group := user_service.GroupPrimaryKey{
 		Id: id,
 	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Groups().GetById(c.Request.Context(), &group)
+	}) {
+		return
+	}
+
 	_, err := h.grpcClient.Groups().Delete(c.Request.Context(), &group)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting a group", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting a group")
 		return
 	}
 	handleResponse(c, h.log, "Group deleted successfully", http.StatusOK, "Group deleted successfully")
@@ -145,38 +219,54 @@ func (h *handler) DeleteGroup(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	is_active query boolean false "true or false"
+// @Param    	level query string false "course level, e.g. beginner"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,id"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,name,branch.name"
+// @Param    	expand query string false "comma separated related records to embed: branch"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllGroups(c *gin.Context) {
 	search := c.Query("search")
 
-	page, err := ParsePageQueryParam(c)
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
-		return
-	}
-	limit, err := ParseLimitQueryParam(c)
-
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
 
 	groups := user_service.GetListGroupsRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
 		Search: search,
 	}
 
-	resp, err := h.grpcClient.Groups().GetAll(c.Request.Context(), &groups)
+	q, err := bindListQuery(c, &groups, &user_service.Group{})
+	if err != nil {
+		handleError(c, h.log, err, "error while parsing filters")
+		return
+	}
+
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		groups.Offset, groups.Limit = offset, limit
+		return h.grpcClient.Groups().GetAll(ctx, &groups)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all groups", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all groups")
 		return
 	}
-	handleResponse(c, h.log, "Groups got successfully", http.StatusOK, resp)
+
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	handleListResponse(c, h.log, "Groups got successfully", data, p)
 }
@@ -1,14 +1,26 @@
 package handler
 
 import (
-	"net/http"
-	"strconv"
+	"context"
 	"crmapi/api/models"
 	"crmapi/config"
+	"crmapi/pkg"
 	"crmapi/pkg/grpc_client"
+	"crmapi/pkg/i18n"
 	"crmapi/pkg/logger"
+	"crmapi/pkg/otp"
+	"crmapi/pkg/rbac"
+	"crmapi/pkg/sanitize"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -17,6 +29,10 @@ type handler struct {
 	log        logger.Logger
 	grpcClient *grpc_client.GrpcClient
 	cfg        config.Config
+	policy     *rbac.Policy
+	ready      *atomic.Bool
+	locks      *recordLocks
+	passwords  *otp.Limiter // password change attempts per user
 }
 
 // HandlerV1Config ...
@@ -24,6 +40,8 @@ type HandlerConfig struct {
 	Logger     logger.Logger
 	GrpcClient *grpc_client.GrpcClient
 	Cfg        config.Config
+	Policy     *rbac.Policy
+	Ready      *atomic.Bool // cleared on shutdown so that readiness fails
 }
 
 const (
@@ -51,124 +69,234 @@ const (
 	ErrorCodeWrongClub = "WRONG_CLUB"
 	// ErrorCodePasswordsNotEqual ...
 	ErrorCodePasswordsNotEqual = "PASSWORDS_NOT_EQUAL"
+	// ErrorCodeTimeout ...
+	ErrorCodeTimeout = "TIMEOUT"
+	// ErrorCodeCanceled ...
+	ErrorCodeCanceled = "CANCELED"
+	// ErrorCodeUnavailable ...
+	ErrorCodeUnavailable = "UNAVAILABLE"
+	// ErrorCodeTooManyRequests ...
+	ErrorCodeTooManyRequests = "TOO_MANY_REQUESTS"
+	// ErrorCodeFailedPrecondition ...
+	ErrorCodeFailedPrecondition = "FAILED_PRECONDITION"
+	// ErrorCodeConflict ...
+	ErrorCodeConflict = "CONFLICT"
+	// ErrorCodeNotImplemented ...
+	ErrorCodeNotImplemented = "NOT_IMPLEMENTED"
 )
 
+// sanitizer strips sensitive fields from every response and from logged data
+var sanitizer = sanitize.New([]string{"password"})
+
 // New ...
 func New(c *HandlerConfig) *handler {
+	sanitizer = sanitize.New(c.Cfg.SensitiveFields)
+
+	ready := c.Ready
+	if ready == nil {
+		ready = &atomic.Bool{}
+		ready.Store(true)
+	}
+	if i18n.Supported(c.Cfg.DefaultLanguage) {
+		defaultLanguage = c.Cfg.DefaultLanguage
+	}
+
 	return &handler{
 		log:        c.Logger,
 		grpcClient: c.GrpcClient,
 		cfg:        c.Cfg,
+		policy:     c.Policy,
+		ready:      ready,
+		locks:      newRecordLocks(),
+		passwords:  otp.NewLimiter(c.Cfg.PasswordChangeWindow, c.Cfg.PasswordChangeMaxAttempts),
 	}
 }
 
-func handleGrpcErrWithDescription(c *gin.Context, l logger.Logger, err error, message string) bool {
-	st, ok := status.FromError(err)
-	if !ok || st.Code() == codes.Internal {
-		c.JSON(http.StatusInternalServerError, models.ErrorWithDescription{
-			Code:        http.StatusBadRequest,
-			Description: st.Message(),
-		})
-		l.Error(message, logger.Error(err))
-		return true
-	}
-	if st.Code() == codes.NotFound {
-		c.JSON(http.StatusNotFound, models.ErrorWithDescription{
-			Code:        http.StatusNotFound,
-			Description: st.Message(),
-		})
-		l.Error(message+", not found", logger.Error(err))
-		return true
-	} else if st.Code() == codes.Unavailable {
-		c.JSON(http.StatusInternalServerError, models.ErrorWithDescription{
-			Code:        http.StatusInternalServerError,
-			Description: "Internal Server Error",
-		})
-		l.Error(message+", service unavailable", logger.Error(err))
-		return true
-	} else if st.Code() == codes.AlreadyExists {
-		c.JSON(http.StatusInternalServerError, models.ErrorWithDescription{
-			Code:        http.StatusInternalServerError,
-			Description: st.Message(),
-		})
-		l.Error(message+", already exists", logger.Error(err))
-		return true
-	} else if st.Code() == codes.InvalidArgument {
-		c.JSON(http.StatusBadRequest, models.ErrorWithDescription{
-			Code:        http.StatusBadRequest,
-			Description: st.Message(),
-		})
-		l.Error(message+", invalid field", logger.Error(err))
-		return true
-	} else if st.Code() == codes.Code(20) {
-		c.JSON(http.StatusBadRequest, models.ErrorWithDescription{
-			Code:        http.StatusBadRequest,
-			Description: st.Message(),
-		})
-		l.Error(message+", invalid field", logger.Error(err))
-		return true
-	} else if st.Err() != nil {
-		c.JSON(http.StatusBadRequest, models.ErrorWithDescription{
-			Code:        http.StatusBadRequest,
-			Description: st.Message(),
-		})
-		l.Error(message+", invalid field", logger.Error(err))
-		return true
-	}
-	return false
+// grpcErrors maps every gRPC status code to an http status and an error code
+var grpcErrors = map[codes.Code]struct {
+	status int
+	code   string
+}{
+	codes.Canceled:           {statusClientClosedRequest, ErrorCodeCanceled},
+	codes.Unknown:            {http.StatusInternalServerError, ErrorCodeInternal},
+	codes.InvalidArgument:    {http.StatusBadRequest, ErrorBadRequest},
+	codes.DeadlineExceeded:   {http.StatusGatewayTimeout, ErrorCodeTimeout},
+	codes.NotFound:           {http.StatusNotFound, ErrorCodeNotFound},
+	codes.AlreadyExists:      {http.StatusConflict, ErrorCodeAlreadyExists},
+	codes.PermissionDenied:   {http.StatusForbidden, ErrorCodeForbidden},
+	codes.ResourceExhausted:  {http.StatusTooManyRequests, ErrorCodeTooManyRequests},
+	codes.FailedPrecondition: {http.StatusBadRequest, ErrorCodeFailedPrecondition},
+	codes.Aborted:            {http.StatusConflict, ErrorCodeConflict},
+	codes.OutOfRange:         {http.StatusBadRequest, ErrorBadRequest},
+	codes.Unimplemented:      {http.StatusNotImplemented, ErrorCodeNotImplemented},
+	codes.Internal:           {http.StatusInternalServerError, ErrorCodeInternal},
+	codes.Unavailable:        {http.StatusServiceUnavailable, ErrorCodeUnavailable},
+	codes.DataLoss:           {http.StatusInternalServerError, ErrorCodeInternal},
+	codes.Unauthenticated:    {http.StatusUnauthorized, ErrorCodeUnauthorized},
 }
 
-func handleResponse(c *gin.Context, log logger.Logger, msg string, statusCode int, data interface{}) {
-	resp := models.Response{}
+// statusClientClosedRequest is the non standard status used when the client went away
+const statusClientClosedRequest = 499
 
-	if statusCode >= 100 && statusCode <= 199 {
-		resp.Description = config.ERR_INFORMATION
-	} else if statusCode >= 200 && statusCode <= 299 {
-		resp.Description = config.SUCCESS
-		log.Info("REQUEST SUCCEEDED", logger.Any("msg: ", msg), logger.Int("status: ", statusCode))
-	} else if statusCode >= 300 && statusCode <= 399 {
-		resp.Description = config.ERR_REDIRECTION
-	} else if statusCode >= 400 && statusCode <= 499 {
-		resp.Description = config.ERR_BADREQUEST
-		log.Error("BAD REQUEST", logger.Any("error: ", msg), logger.Any("data: ", data))
-	} else {
-		resp.Description = config.ERR_INTERNAL_SERVER
-		log.Error("ERR_INTERNAL_SERVER", logger.Any("error: ", msg))
+// handleError is the single place where handler errors are turned into responses.
+// gRPC statuses are mapped by code, validation errors are listed per field, errors
+// of request binding are reported as invalid json and anything else is internal.
+func handleError(c *gin.Context, l logger.Logger, err error, message string) {
+	var validationErrs pkg.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		handleProblem(c, l, http.StatusBadRequest, ErrorBadRequest, message, validationErrs)
+		return
 	}
 
-	resp.StatusCode = statusCode
-	resp.Data = data
+	if errors.Is(err, errOtherBranch) {
+		handleProblem(c, l, http.StatusForbidden, ErrorCodeWrongClub, message, err.Error())
+		return
+	}
 
-	c.JSON(resp.StatusCode, resp)
+	var open *grpc_client.CircuitOpenError
+	if errors.As(err, &open) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(open.RetryAfter.Seconds()))))
+		handleProblem(c, l, http.StatusServiceUnavailable, ErrorCodeUnavailable, message, open.Error())
+		return
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		handleProblem(c, l, http.StatusGatewayTimeout, ErrorCodeTimeout, message, err.Error())
+		return
+	case errors.Is(err, context.Canceled):
+		handleProblem(c, l, statusClientClosedRequest, ErrorCodeCanceled, message, err.Error())
+		return
+	}
+
+	if isDecodeError(err) {
+		handleProblem(c, l, http.StatusBadRequest, ErrorCodeInvalidJSON, message, err.Error())
+		return
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		// a failure of the gateway itself, the error is logged but not returned
+		handleProblem(c, l, http.StatusInternalServerError, ErrorCodeInternal, message+": "+err.Error(), http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	mapped, ok := grpcErrors[st.Code()]
+	if !ok {
+		// custom codes used by the services for field errors
+		mapped.status, mapped.code = http.StatusBadRequest, ErrorBadRequest
+	}
+
+	if mapped.status >= http.StatusInternalServerError {
+		// backend internals are logged, not returned
+		handleProblem(c, l, mapped.status, mapped.code, message+": "+st.Code().String()+": "+st.Message(), http.StatusText(mapped.status))
+		return
+	}
+
+	handleProblem(c, l, mapped.status, mapped.code, message, st.Message())
+}
+
+// isDecodeError reports whether err comes from reading a malformed request body
+func isDecodeError(err error) bool {
+	var (
+		syntaxErr   *json.SyntaxError
+		typeErr     *json.UnmarshalTypeError
+		maxBytesErr *http.MaxBytesError
+		bindingErrs validator.ValidationErrors
+	)
+
+	return errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.As(err, &maxBytesErr) ||
+		errors.As(err, &bindingErrs) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
 }
 
-func ParsePageQueryParam(c *gin.Context) (int64, error) {
-	pageStr := c.Query("page")
-	if pageStr == "" {
-		pageStr = "1"
+// handleProblem writes an RFC 7807 application/problem+json response and aborts the chain.
+// detail is either a string or a list of field errors.
+func handleProblem(c *gin.Context, l logger.Logger, statusCode int, errorCode, message string, detail interface{}) {
+	l = requestLogger(c, l)
+
+	problem := models.Problem{
+		Type:      errorCode,
+		Title:     localize(c, errorCode, http.StatusText(statusCode)),
+		Status:    statusCode,
+		Instance:  c.Request.URL.Path,
+		RequestID: requestID(c),
 	}
-	page, err := strconv.ParseInt(pageStr, 10, 30)
-	if err != nil {
-		return 0, err
+	if problem.Title == "" {
+		problem.Title = errorCode
 	}
-	if page == 0 {
-		return 1, nil
+
+	if errs, ok := detail.(pkg.ValidationErrors); ok {
+		detail = localizeErrors(c, errs)
 	}
-	return page, nil
+
+	switch d := detail.(type) {
+	case string:
+		problem.Detail = d
+	case nil:
+		problem.Detail = message
+	default:
+		problem.Detail = message
+		problem.Errors = d
+	}
+
+	if statusCode >= http.StatusInternalServerError {
+		l.Error(message, logger.String("type", errorCode), logger.Int("status", statusCode), logger.Any("detail", problem.Detail))
+	} else {
+		l.Warn(message, logger.String("type", errorCode), logger.Int("status", statusCode), logger.Any("detail", problem.Detail))
+	}
+
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(statusCode, problem)
 }
 
-func ParseLimitQueryParam(c *gin.Context) (int64, error) {
-	limitStr := c.Query("limit")
-	if limitStr == "" {
-		limitStr = "10"
+// errorCodeForStatus picks the error code for responses written with a plain http status
+func errorCodeForStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return ErrorCodeForbidden
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusConflict:
+		return ErrorCodeAlreadyExists
+	case http.StatusServiceUnavailable:
+		return ErrorCodeUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrorCodeTimeout
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return ErrorCodeInternal
 	}
-	limit, err := strconv.ParseInt(limitStr, 10, 30)
-	if err != nil {
-		return 0, err
+	return ErrorBadRequest
+}
+
+func handleResponse(c *gin.Context, log logger.Logger, msg string, statusCode int, data interface{}) {
+	writeResponse(c, log, msg, statusCode, data, nil)
+}
+
+func writeResponse(c *gin.Context, log logger.Logger, msg string, statusCode int, data interface{}, pagination *models.Pagination) {
+	if statusCode >= 400 {
+		handleProblem(c, log, statusCode, errorCodeForStatus(statusCode), msg, sanitizer.Clean(data))
+		return
 	}
 
-	if limit == 0 {
-		return 10, nil
+	log = requestLogger(c, log)
+	resp := models.Response{}
+	data = sanitizer.Clean(data)
+
+	if statusCode >= 100 && statusCode <= 199 {
+		resp.Description = localize(c, i18n.KeyInformation, config.ERR_INFORMATION)
+	} else if statusCode >= 200 && statusCode <= 299 {
+		resp.Description = localize(c, i18n.KeySuccess, config.SUCCESS)
+		log.Info("REQUEST SUCCEEDED", logger.Any("msg: ", msg), logger.Int("status: ", statusCode))
+	} else {
+		resp.Description = localize(c, i18n.KeyRedirection, config.ERR_REDIRECTION)
 	}
-	return limit, nil
+
+	resp.StatusCode = statusCode
+	resp.Data = data
+	resp.Pagination = pagination
+
+	c.JSON(resp.StatusCode, resp)
 }
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"crmapi/api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	healthOK           = "ok"
+	healthUnavailable  = "unavailable"
+	healthShuttingDown = "shutting_down"
+)
+
+// Healthz godoc
+// @Router 		/healthz [GET]
+// @Summary 	Liveness probe
+// @Description Answers 200 while the process is alive, it does not check any dependency
+// @Tags 		HEALTH
+// @Produce  	json
+// @Success		200  {object} models.HealthResponse
+func (h *handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, models.HealthResponse{Status: healthOK})
+}
+
+// Readyz godoc
+// @Router 		/readyz [GET]
+// @Summary 	Readiness probe
+// @Description Checks the configuration and both gRPC backends through the gRPC health protocol, fails while the gateway shuts down
+// @Tags 		HEALTH
+// @Produce  	json
+// @Success		200  {object} models.HealthResponse
+// @Failure 	503  {object} models.HealthResponse
+func (h *handler) Readyz(c *gin.Context) {
+	if !h.ready.Load() {
+		c.JSON(http.StatusServiceUnavailable, models.HealthResponse{Status: healthShuttingDown})
+		return
+	}
+
+	resp := models.HealthResponse{Status: healthOK, Checks: map[string]models.HealthCheck{}}
+
+	config := models.HealthCheck{Status: healthOK}
+	if err := h.cfg.Validate(); err != nil {
+		config = models.HealthCheck{Status: healthUnavailable, Error: err.Error()}
+		resp.Status = healthUnavailable
+	}
+	resp.Checks["config"] = config
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.cfg.ReadinessTimeout)
+	defer cancel()
+
+	for _, s := range h.grpcClient.CheckHealth(ctx, false) {
+		check := models.HealthCheck{
+			Status:    healthOK,
+			State:     s.State,
+			Circuit:   s.Circuit,
+			LatencyMs: float64(s.Latency.Microseconds()) / 1000,
+			Error:     s.Error,
+		}
+		if !s.Serving {
+			check.Status = healthUnavailable
+			resp.Status = healthUnavailable
+		}
+		resp.Checks[s.Name] = check
+	}
+
+	statusCode := http.StatusOK
+	if resp.Status != healthOK {
+		statusCode = http.StatusServiceUnavailable
+	}
+	c.JSON(statusCode, resp)
+}
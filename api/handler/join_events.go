@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"crmapi/config"
 	user_service "crmapi/genproto/user_service/join_events"
+	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -18,25 +20,33 @@ import (
 // @Produce  	json
 // @Param		join-event body user_service.CreateJoinEvent true "join-event"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateJoinEvent(c *gin.Context) {
 	evenJoin := user_service.CreateJoinEvent{}
 
 	if err := c.ShouldBindJSON(&evenJoin); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a evenJoin")
+		handleError(c, h.log, err, "error while creating a evenJoin")
+		return
+	}
+
+	if err := pkg.Validate(&evenJoin); err != nil {
+		handleError(c, h.log, err, "error while validating join event")
+		return
+	}
+
+	if !h.checkOwnStudent(c, evenJoin.StudentId) {
 		return
 	}
 
 	resp, err := h.grpcClient.JoinEvens().Create(c.Request.Context(), &evenJoin)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a evenJoin")
+		handleError(c, h.log, err, "error while creating a evenJoin")
 		return
 	}
 	handleResponse(c, h.log, "Added successfully", http.StatusCreated, resp)
-	handleGrpcErrWithDescription(c, h.log, err, "EvenJoin created successfully")
 }
 
 // GetByIdEventJoin godoc
@@ -49,14 +59,14 @@ func (h *handler) CreateJoinEvent(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdEventJoin(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating join event id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating join event id", err.Error())
 		return
 	}
 
@@ -67,7 +77,11 @@ func (h *handler) GetByIdEventJoin(c *gin.Context) {
 	resp, err := h.grpcClient.JoinEvens().GetById(c.Request.Context(), &joinevent)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting joinevent", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting joinevent")
+		return
+	}
+
+	if !h.checkOwnStudent(c, resp.StudentId) {
 		return
 	}
 
@@ -84,14 +98,14 @@ func (h *handler) GetByIdEventJoin(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteEventJoin(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating join event id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating join event id", err.Error())
 		return
 	}
 
@@ -101,8 +115,20 @@ func (h *handler) DeleteEventJoin(c *gin.Context) {
 	_, err := h.grpcClient.JoinEvens().Delete(c.Request.Context(), &joinEvent)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting a join event", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting a join event")
 		return
 	}
 	handleResponse(c, h.log, "Join event deleted successfully", http.StatusOK, "Join event deleted successfully")
-}
\ No newline at end of file
+}
+
+// checkOwnStudent writes ErrorCodeForbidden and returns false when a student works with
+// the join event of another student, students may only join events themselves
+func (h *handler) checkOwnStudent(c *gin.Context, studentID string) bool {
+	auth := getAuthInfo(c)
+	if auth.UserRole != config.STUDENT_TYPE || studentID == auth.UserID {
+		return true
+	}
+
+	handleProblem(c, h.log, http.StatusForbidden, ErrorCodeForbidden, "student may join events only for itself", nil)
+	return false
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"crmapi/pkg"
+	"crmapi/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ctxLanguage = "language"
+
+// defaultLanguage is used when neither the caller's preference nor Accept-Language match a supported language
+var defaultLanguage = i18n.English
+
+// language returns the language of the response: the preference stored in the
+// caller's token first, then the Accept-Language header
+func language(c *gin.Context) string {
+	if lang := c.GetString(ctxLanguage); i18n.Supported(lang) {
+		return lang
+	}
+	return i18n.Match(c.GetHeader("Accept-Language"), defaultLanguage)
+}
+
+// localize translates key into the language of the request, returning fallback for unknown keys
+func localize(c *gin.Context, key, fallback string) string {
+	if msg, ok := i18n.T(language(c), key); ok {
+		return msg
+	}
+	return fallback
+}
+
+// localizeErrors translates the messages of validation errors, keeping the original
+// message of rules without a catalog entry (e.g. custom regex messages)
+func localizeErrors(c *gin.Context, errs pkg.ValidationErrors) pkg.ValidationErrors {
+	lang := language(c)
+
+	localized := make(pkg.ValidationErrors, len(errs))
+	for i, e := range errs {
+		if msg, ok := i18n.T(lang, i18n.RulePrefix+e.Key, e.Args...); ok {
+			e.Message = msg
+		}
+		localized[i] = e
+	}
+	return localized
+}
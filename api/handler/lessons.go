@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"crmapi/genproto/schedule_service/lessons"
+	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateLesson godoc
@@ -18,24 +21,35 @@ import (
 // @Produce  	json
 // @Param		schedule body schedule_service.CreateLesson true "schedule"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateLesson(c *gin.Context) {
 	lesson := schedule_service.CreateLesson{}
 
 	if err := c.ShouldBindJSON(&lesson); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a lesson")
+		handleError(c, h.log, err, "error while creating a lesson")
 		return
 	}
 
-	_, err := h.grpcClient.LessonsService().Create(c.Request.Context(), &lesson)
+	if err := pkg.Validate(&lesson); err != nil {
+		handleError(c, h.log, err, "error while validating lesson")
+		return
+	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.scheduleBranch(c.Request.Context(), lesson.ScheduleId)
+	}) {
+		return
+	}
+
+	resp, err := h.grpcClient.LessonsService().Create(c.Request.Context(), &lesson)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a lesson")
+		handleError(c, h.log, err, "error while creating a lesson")
 		return
 	}
-	handleGrpcErrWithDescription(c, h.log, err, "Lesson created successfully")
+	handleResponse(c, h.log, "Lesson created successfully", http.StatusCreated, resp)
 }
 
 // GetByIdLesson godoc
@@ -47,15 +61,16 @@ func (h *handler) CreateLesson(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,schedule_id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdLesson(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating lesson id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating lesson id", err.Error())
 		return
 	}
 
@@ -66,11 +81,23 @@ func (h *handler) GetByIdLesson(c *gin.Context) {
 	resp, err := h.grpcClient.LessonsService().GetById(c.Request.Context(), &lesson)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting lesson", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting lesson")
+		return
+	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.scheduleBranch(c.Request.Context(), resp.ScheduleId)
+	}) {
 		return
 	}
 
-	handleResponse(c, h.log, "Lesson got successfully", http.StatusOK, resp)
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	setETag(c, resp)
+	handleResponse(c, h.log, "Lesson got successfully", http.StatusOK, data)
 }
 
 // UpdateLesson godoc
@@ -83,26 +110,74 @@ func (h *handler) GetByIdLesson(c *gin.Context) {
 // @Produce  	json
 // @Param		schedule body schedule_service.UpdateLesson true "schedule"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateLesson(c *gin.Context) {
 	lesson := schedule_service.UpdateLesson{}
 
 	if err := c.ShouldBindJSON(&lesson); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if err := pkg.Validate(&lesson); err != nil {
+		handleError(c, h.log, err, "error while validating lesson")
+		return
+	}
+
+	unlock := h.locks.lock(lesson.Id)
+	defer unlock()
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.scheduleBranch(c.Request.Context(), lesson.ScheduleId)
+	}) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.lessonBranch(c.Request.Context(), lesson.Id)
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.LessonsService().Update(c.Request.Context(), &lesson)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating a lesson", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating a lesson")
 		return
 	}
 	handleResponse(c, h.log, "Lesson updated successfully", http.StatusOK, resp)
 }
 
+// PatchLesson godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/lesson/{id} [PATCH]
+// @Summary 	Partially update a lesson
+// @Description API for changing only the given fields of a lesson, fields left out keep their stored value. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		LESSONS
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the lesson"
+// @Param		lesson body schedule_service.UpdateLesson true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchLesson(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "lesson",
+		update: &schedule_service.UpdateLesson{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.LessonsService().GetById(ctx, &schedule_service.LessonPrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.LessonsService().Update(ctx, update.(*schedule_service.UpdateLesson))
+		},
+		branch: func(ctx context.Context, record proto.Message) (branchRecord, error) {
+			return h.scheduleBranch(ctx, record.(scheduleRecord).GetScheduleId())
+		},
+	})
+}
+
 // GetAllLessons godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/lessons [GET]
@@ -111,37 +186,48 @@ func (h *handler) UpdateLesson(c *gin.Context) {
 // @Tags 		LESSONS
 // @Accept  	json
 // @Produce  	json
-// @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	schedule_id query string false "schedule id"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,id"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,schedule_id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllLessons(c *gin.Context) {
 
-	page, err := ParsePageQueryParam(c)
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
-	limit, err := ParseLimitQueryParam(c)
 
+	lessons := schedule_service.GetListLessonRequest{}
+
+	q, err := bindListQuery(c, &lessons, &schedule_service.Lesson{})
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing filters")
 		return
 	}
 
-	lessons := schedule_service.GetListLessonRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
-	}
-
-	resp, err := h.grpcClient.LessonsService().GetAll(c.Request.Context(), &lessons)
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		lessons.Offset, lessons.Limit = offset, limit
+		return h.grpcClient.LessonsService().GetAll(ctx, &lessons)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all lessons", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all lessons")
+		return
+	}
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
-	handleResponse(c, h.log, "Lessons got successfully", http.StatusOK, resp)
+
+	handleListResponse(c, h.log, "Lessons got successfully", data, p)
 }
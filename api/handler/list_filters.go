@@ -0,0 +1,359 @@
+package handler
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	at "crmapi/genproto/schedule_service/attendances"
+	ls "crmapi/genproto/schedule_service/lessons"
+	sc "crmapi/genproto/schedule_service/schedules"
+	tk "crmapi/genproto/schedule_service/tasks"
+	ad "crmapi/genproto/user_service/administrators"
+	br "crmapi/genproto/user_service/branches"
+	ev "crmapi/genproto/user_service/events"
+	gr "crmapi/genproto/user_service/groups"
+	mn "crmapi/genproto/user_service/managers"
+	st "crmapi/genproto/user_service/students"
+	sp "crmapi/genproto/user_service/super_admins"
+	ss "crmapi/genproto/user_service/support_teachers"
+	ts "crmapi/genproto/user_service/teachers"
+	"crmapi/pkg"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const sortParam = "sort"
+
+// filterKind is how the value of a filter param is read and compared with its record field
+type filterKind int
+
+const (
+	filterID   filterKind = iota // a uuid equal to the field
+	filterBool                   // true or false, is_active is stored as 0 or 1
+	filterEnum                   // a value name or number of the enum field
+	filterFrom                   // a time or number the field is at least
+	filterTo                     // a time or number the field is at most, a date includes the whole day
+	filterDebt                   // true or false, whether paid_sum is below total_sum
+)
+
+// listFilter is a typed filter param of a list, applied to one field of its records
+type listFilter struct {
+	field protoreflect.Name
+	kind  filterKind
+}
+
+// filterTimeLayouts are the accepted formats of time filters and of stored times
+var filterTimeLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly}
+
+// listFilters holds the filter params of each list by the full name of its records
+var listFilters = map[protoreflect.FullName]map[string]listFilter{}
+
+// registerFilters declares the filters of a list, unknown fields panic like pkg.Register
+func registerFilters(record proto.Message, filters map[string]listFilter) {
+	desc := record.ProtoReflect().Descriptor()
+	for param, f := range filters {
+		if desc.Fields().ByName(f.field) == nil {
+			panic(fmt.Sprintf("list filters: %s has no field %q for %q", desc.FullName(), f.field, param))
+		}
+	}
+	listFilters[desc.FullName()] = filters
+}
+
+// createdFilters are the created_at range filters shared by most lists
+func createdFilters(extra map[string]listFilter) map[string]listFilter {
+	filters := map[string]listFilter{
+		"created_from": {"created_at", filterFrom},
+		"created_to":   {"created_at", filterTo},
+	}
+	for param, f := range extra {
+		filters[param] = f
+	}
+	return filters
+}
+
+func init() {
+	active := listFilter{"is_active", filterBool}
+	group := listFilter{"group_id", filterID}
+
+	registerFilters(&st.Student{}, createdFilters(map[string]listFilter{
+		"group_id":  group,
+		"is_active": active,
+		"has_debt":  {"paid_sum", filterDebt},
+	}))
+	registerFilters(&ts.Teacher{}, createdFilters(map[string]listFilter{
+		"group_id":           group,
+		"is_active":          active,
+		"support_teacher_id": {"support_teacher_id", filterID},
+	}))
+	registerFilters(&ss.SupportTeacher{}, createdFilters(map[string]listFilter{
+		"group_id":  group,
+		"is_active": active,
+	}))
+	registerFilters(&mn.Manager{}, createdFilters(map[string]listFilter{"is_active": active}))
+	registerFilters(&ad.Adminstrator{}, createdFilters(map[string]listFilter{"is_active": active}))
+	registerFilters(&sp.SuperAdmin{}, createdFilters(nil))
+	registerFilters(&br.Branch{}, createdFilters(map[string]listFilter{"is_active": active}))
+	registerFilters(&gr.Group{}, createdFilters(map[string]listFilter{
+		"is_active": active,
+		"level":     {"level", filterEnum},
+	}))
+	registerFilters(&ev.Event{}, createdFilters(map[string]listFilter{"is_active": active}))
+	registerFilters(&sc.Schedule{}, createdFilters(map[string]listFilter{
+		"group_id":           group,
+		"teacher_id":         {"teacher_id", filterID},
+		"support_teacher_id": {"support_teacher_id", filterID},
+		"from":               {"start_time", filterFrom},
+		"to":                 {"start_time", filterTo},
+	}))
+	registerFilters(&ls.Lesson{}, createdFilters(map[string]listFilter{"schedule_id": {"schedule_id", filterID}}))
+	registerFilters(&tk.Task{}, createdFilters(map[string]listFilter{
+		"lesson_id":     {"lesson_id", filterID},
+		"deadline_from": {"deadline", filterFrom},
+		"deadline_to":   {"deadline", filterTo},
+	}))
+	registerFilters(&at.Attendance{}, map[string]listFilter{
+		"lesson_id":  {"lesson_id", filterID},
+		"student_id": {"student_id", filterID},
+		"status":     {"status", filterEnum},
+	})
+}
+
+// recordCheck reports whether a record passes one filter
+type recordCheck func(record protoreflect.Message) bool
+
+// parseFilter reads the value of a filter param into a check of its record field
+func parseFilter(param, raw string, f listFilter, desc protoreflect.MessageDescriptor) (recordCheck, *pkg.FieldError) {
+	fd := desc.Fields().ByName(f.field)
+
+	switch f.kind {
+	case filterID:
+		if uuid.Validate(raw) != nil {
+			return nil, &pkg.FieldError{Field: param, Rule: "uuid", Message: "must be a valid uuid", Key: "uuid"}
+		}
+		return func(record protoreflect.Message) bool {
+			return strings.EqualFold(record.Get(fd).String(), raw)
+		}, nil
+
+	case filterBool, filterDebt:
+		want, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, &pkg.FieldError{Field: param, Rule: "bool", Message: "must be true or false", Key: "bool"}
+		}
+		if f.kind == filterDebt {
+			total := desc.Fields().ByName("total_sum")
+			return func(record protoreflect.Message) bool {
+				return (record.Get(fd).Float() < record.Get(total).Float()) == want
+			}, nil
+		}
+		return func(record protoreflect.Message) bool {
+			return (toNumber(record.Get(fd), fd) != 0) == want
+		}, nil
+
+	case filterEnum:
+		values := fd.Enum().Values()
+		var value protoreflect.EnumValueDescriptor
+		if n, err := strconv.ParseInt(raw, 10, 32); err == nil {
+			value = values.ByNumber(protoreflect.EnumNumber(n))
+		}
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			if strings.EqualFold(string(values.Get(i).Name()), raw) {
+				value = values.Get(i)
+			}
+			names = append(names, string(values.Get(i).Name()))
+		}
+		if value == nil {
+			return nil, &pkg.FieldError{Field: param, Rule: "enum", Message: "must be one of " + strings.Join(names, ", "), Key: "enum_values", Args: []interface{}{strings.Join(names, ", ")}}
+		}
+		return func(record protoreflect.Message) bool {
+			return record.Get(fd).Enum() == value.Number()
+		}, nil
+	}
+
+	// from and to
+	if fd.Kind() != protoreflect.StringKind {
+		bound, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, &pkg.FieldError{Field: param, Rule: "number", Message: "must be a number", Key: "number"}
+		}
+		return func(record protoreflect.Message) bool {
+			v := toNumber(record.Get(fd), fd)
+			if f.kind == filterFrom {
+				return v >= bound
+			}
+			return v <= bound
+		}, nil
+	}
+
+	bound, layout, ok := parseTime(raw)
+	if !ok {
+		layouts := strings.Join(filterTimeLayouts, " / ")
+		return nil, &pkg.FieldError{Field: param, Rule: "time", Message: "must be a time in format " + layouts, Key: "time", Args: []interface{}{layouts}}
+	}
+	if f.kind == filterTo && layout == time.DateOnly {
+		bound = bound.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return func(record protoreflect.Message) bool {
+		v, _, ok := parseTime(record.Get(fd).String())
+		if !ok {
+			return false
+		}
+		if f.kind == filterFrom {
+			return !v.Before(bound)
+		}
+		return !v.After(bound)
+	}, nil
+}
+
+// parseTime parses a time in any of filterTimeLayouts and returns the layout it matched
+func parseTime(value string) (time.Time, string, bool) {
+	for _, layout := range filterTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, layout, true
+		}
+	}
+	return time.Time{}, "", false
+}
+
+// sortKey orders records by one field, descending when the sort param has a leading "-"
+type sortKey struct {
+	field protoreflect.FieldDescriptor
+	desc  bool
+}
+
+// parseSort reads sort=field,-field. Any scalar field of the records can be sorted by,
+// except the write-only ones.
+func parseSort(raw string, desc protoreflect.MessageDescriptor) ([]sortKey, error) {
+	var (
+		keys []sortKey
+		errs pkg.ValidationErrors
+	)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		name := strings.TrimPrefix(part, "-")
+		if name == "" {
+			continue
+		}
+
+		fd := desc.Fields().ByName(protoreflect.Name(name))
+		if fd == nil || fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.MessageKind || writeOnlyFields[fd.Name()] {
+			errs = append(errs, pkg.FieldError{Field: sortParam, Rule: "unknown_field", Message: name + " is not a field of this resource", Key: "unknown_field", Args: []interface{}{name}})
+			continue
+		}
+		keys = append(keys, sortKey{field: fd, desc: name != part})
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return keys, nil
+}
+
+// sortRecords orders records by the keys
+func sortRecords(records []protoreflect.Value, keys []sortKey) {
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i].Message(), records[j].Message()
+		for _, key := range keys {
+			if c := compareField(a.Get(key.field), b.Get(key.field), key.field); c != 0 {
+				return (c < 0) != key.desc
+			}
+		}
+		return false
+	})
+}
+
+// keysetOrder is the order of a list built by the gateway: the sort param, created_at
+// by default, followed by id so that every record has a place of its own
+func keysetOrder(desc protoreflect.MessageDescriptor, keys []sortKey) []sortKey {
+	order := append([]sortKey(nil), keys...)
+	fields := desc.Fields()
+	if fd := fields.ByName("created_at"); fd != nil && len(order) == 0 {
+		order = append(order, sortKey{field: fd})
+	}
+
+	id := fields.ByName("id")
+	for _, key := range order {
+		if key.field == id {
+			return order
+		}
+	}
+	return append(order, sortKey{field: id})
+}
+
+// keyset returns the values of the order fields of a record, the content of a cursor
+func keyset(record protoreflect.Message, order []sortKey) []string {
+	key := make([]string, len(order))
+	for i, k := range order {
+		if v := record.Get(k.field); k.field.Kind() == protoreflect.StringKind {
+			key[i] = v.String()
+		} else {
+			key[i] = strconv.FormatFloat(toNumber(v, k.field), 'g', -1, 64)
+		}
+	}
+	return key
+}
+
+// compareKeyset compares a record with the keyset of a cursor in the order of the list
+func compareKeyset(record protoreflect.Message, key []string, order []sortKey) int {
+	for i, k := range order {
+		var c int
+		if v := record.Get(k.field); k.field.Kind() == protoreflect.StringKind {
+			c = strings.Compare(v.String(), key[i])
+		} else {
+			n, _ := strconv.ParseFloat(key[i], 64)
+			c = compareNumbers(toNumber(v, k.field), n)
+		}
+		if k.desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareField compares two values of a field, strings by their text and the rest as numbers
+func compareField(a, b protoreflect.Value, fd protoreflect.FieldDescriptor) int {
+	if fd.Kind() == protoreflect.StringKind {
+		return strings.Compare(a.String(), b.String())
+	}
+
+	return compareNumbers(toNumber(a, fd), toNumber(b, fd))
+}
+
+func compareNumbers(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// toNumber returns a bool, enum or numeric value as a float
+func toNumber(v protoreflect.Value, fd protoreflect.FieldDescriptor) float64 {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		if v.Bool() {
+			return 1
+		}
+		return 0
+	case protoreflect.EnumKind:
+		return float64(v.Enum())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return float64(v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return float64(v.Uint())
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float()
+	}
+	return 0
+}
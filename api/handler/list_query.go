@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	ls "crmapi/genproto/schedule_service/lessons"
+	"crmapi/pkg"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	branchField   = "branch_id"
+	scheduleField = "schedule_id"
+	lessonField   = "lesson_id"
+	countField    = "Count"
+)
+
+// listQuery holds the filters and the sort of a list that the backends cannot apply.
+// The gateway applies them itself by scanning the whole backend list, see listRecords.
+type listQuery struct {
+	record protoreflect.MessageDescriptor
+	branch string // only records of this branch, the caller's branch for branch scoped callers
+	checks []recordCheck
+	sort   []sortKey
+}
+
+// scan reports whether the list has to be filtered or sorted on the gateway
+func (q listQuery) scan() bool {
+	return q.branch != "" || len(q.checks) > 0 || len(q.sort) > 0
+}
+
+// listFetch gets one backend page of a list, it sets offset and limit on the list request
+type listFetch func(ctx context.Context, offset, limit int64) (proto.Message, error)
+
+// bindListQuery reads the filters of a list, record is an empty list item. Query params
+// named like a string field of the list request (search) are copied into it and validated
+// with the rules registered for the message. branch_id, the typed filters of listFilters
+// and sort are applied by the gateway. Other params are ignored, like the backends do.
+func bindListQuery(c *gin.Context, req, record proto.Message) (listQuery, error) {
+	desc := record.ProtoReflect().Descriptor()
+	q := listQuery{record: desc}
+	msg := req.ProtoReflect()
+	fields := msg.Descriptor().Fields()
+	filters := listFilters[desc.FullName()]
+	branched := branchBound(desc)
+
+	names := []string{}
+	for name := range c.Request.URL.Query() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs pkg.ValidationErrors
+	for _, name := range names {
+		value := strings.TrimSpace(c.Query(name))
+		if name == branchField && branched {
+			if value != "" && uuid.Validate(value) != nil {
+				errs = append(errs, pkg.FieldError{Field: name, Rule: "uuid", Message: "must be a valid uuid", Key: "uuid"})
+			}
+			q.branch = value
+			continue
+		}
+
+		if value == "" {
+			continue
+		}
+
+		if name == sortParam {
+			keys, err := parseSort(value, desc)
+			if err != nil {
+				errs = append(errs, err.(pkg.ValidationErrors)...)
+			}
+			q.sort = keys
+			continue
+		}
+
+		if f, ok := filters[name]; ok {
+			check, err := parseFilter(name, value, f, desc)
+			if err != nil {
+				errs = append(errs, *err)
+				continue
+			}
+			q.checks = append(q.checks, check)
+			continue
+		}
+
+		if fd := fields.ByName(protoreflect.Name(name)); fd != nil && fd.Kind() == protoreflect.StringKind && !fd.IsList() {
+			msg.Set(fd, protoreflect.ValueOfString(value))
+		}
+	}
+	if len(errs) > 0 {
+		return q, errs
+	}
+
+	// branch scoped callers may only list their own branch
+	if branchID, scoped := branchScope(c); scoped && branched {
+		if branchID == "" || (q.branch != "" && q.branch != branchID) {
+			return q, errOtherBranch
+		}
+		q.branch = branchID
+	}
+
+	return q, pkg.Validate(req)
+}
+
+// branchBound reports whether the branch of a record is known, from its own branch_id or
+// from the schedule or lesson it belongs to
+func branchBound(desc protoreflect.MessageDescriptor) bool {
+	for _, name := range []protoreflect.Name{branchField, scheduleField, lessonField} {
+		if fd := desc.Fields().ByName(name); fd != nil && fd.Kind() == protoreflect.StringKind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listRecords returns the page p of a list. Without gateway filters, sort or cursor the
+// page is the backend page as it is. Otherwise the backend list is read in pages of
+// MaxPageSize, filtered here, put in keyset order and the page is cut from the matching
+// records: the backends have no filters, so their offset and count cannot be used.
+func (h *handler) listRecords(ctx context.Context, p pagination, q listQuery, fetch listFetch) (proto.Message, pagination, error) {
+	if !q.scan() && p.cursor == nil {
+		resp, err := fetch(ctx, p.offset, p.limit)
+		if err != nil {
+			return nil, p, err
+		}
+		p.total, p.size = listCount(resp.ProtoReflect()), int64(listItems(resp.ProtoReflect()).Len())
+		return resp, p, nil
+	}
+
+	var (
+		resp    protoreflect.Message
+		records []protoreflect.Value
+	)
+	branches := newBranchResolver(h)
+	batch := int64(h.cfg.MaxPageSize)
+	for offset := int64(0); ; offset += batch {
+		page, err := fetch(ctx, offset, batch)
+		if err != nil {
+			return nil, p, err
+		}
+		if resp == nil {
+			resp = page.ProtoReflect()
+		}
+
+		items := listItems(page.ProtoReflect())
+		for i := 0; i < items.Len(); i++ {
+			ok, err := q.match(ctx, branches, items.Get(i).Message())
+			if err != nil {
+				return nil, p, err
+			}
+			if ok {
+				records = append(records, items.Get(i))
+			}
+		}
+
+		if int64(items.Len()) < batch || offset+batch >= listCount(page.ProtoReflect()) {
+			break
+		}
+	}
+
+	order := keysetOrder(q.record, q.sort)
+	sortRecords(records, order)
+
+	total := int64(len(records))
+	start := min(p.offset, total)
+	end := min(start+p.limit, total)
+	if cur := p.cursor; cur != nil {
+		key := cur.After
+		if len(key) == 0 {
+			key = cur.Before
+		}
+		if len(key) != len(order) {
+			return nil, p, pkg.ValidationErrors{{Field: cursorParam, Rule: "cursor", Message: "is not a valid cursor"}}
+		}
+
+		pos := int64(sort.Search(len(records), func(i int) bool {
+			c := compareKeyset(records[i].Message(), key, order)
+			return c > 0 || (c == 0 && len(cur.Before) > 0)
+		}))
+		if len(cur.After) > 0 {
+			start, end = pos, min(pos+p.limit, total)
+		} else {
+			start, end = max(pos-p.limit, 0), pos
+		}
+	}
+
+	items := listItems(resp)
+	items.Truncate(0)
+	for _, record := range records[start:end] {
+		items.Append(record)
+	}
+	if fd := resp.Descriptor().Fields().ByName(countField); fd != nil {
+		resp.Set(fd, protoreflect.ValueOfInt64(total))
+	}
+
+	p.offset, p.total, p.size = start, total, end-start
+	if end > start {
+		p.first, p.last = keyset(records[start].Message(), order), keyset(records[end-1].Message(), order)
+	}
+	return resp.Interface(), p, nil
+}
+
+// match reports whether a record passes the gateway filters
+func (q listQuery) match(ctx context.Context, branches *branchResolver, record protoreflect.Message) (bool, error) {
+	if q.branch != "" {
+		branch, err := branches.branch(ctx, record)
+		if err != nil || branch != q.branch {
+			return false, err
+		}
+	}
+
+	for _, check := range q.checks {
+		if !check(record) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// listItems returns the repeated record field of a list response, e.g. students
+func listItems(resp protoreflect.Message) protoreflect.List {
+	fields := resp.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if fd := fields.Get(i); fd.IsList() && fd.Kind() == protoreflect.MessageKind {
+			return resp.Mutable(fd).List()
+		}
+	}
+
+	return nil
+}
+
+// listCount returns the count of a list response, the number of records of the whole list
+func listCount(resp protoreflect.Message) int64 {
+	if fd := resp.Descriptor().Fields().ByName(countField); fd != nil {
+		return resp.Get(fd).Int()
+	}
+
+	return 0
+}
+
+// branchResolver finds the branch of list records. Lessons, tasks and attendances have no
+// branch_id, their schedules and lessons are looked up once per list.
+type branchResolver struct {
+	h         *handler
+	schedules map[string]string // schedule id to branch id
+	lessons   map[string]string // lesson id to schedule id
+}
+
+func newBranchResolver(h *handler) *branchResolver {
+	return &branchResolver{h: h, schedules: map[string]string{}, lessons: map[string]string{}}
+}
+
+// branch returns the branch of a record, empty when its schedule or lesson no longer exists
+func (r *branchResolver) branch(ctx context.Context, record protoreflect.Message) (string, error) {
+	fields := record.Descriptor().Fields()
+	if fd := fields.ByName(branchField); fd != nil {
+		return record.Get(fd).String(), nil
+	}
+	if fd := fields.ByName(scheduleField); fd != nil {
+		return r.schedule(ctx, record.Get(fd).String())
+	}
+	if fd := fields.ByName(lessonField); fd != nil {
+		return r.lesson(ctx, record.Get(fd).String())
+	}
+
+	return "", nil
+}
+
+func (r *branchResolver) schedule(ctx context.Context, id string) (string, error) {
+	if branch, ok := r.schedules[id]; ok {
+		return branch, nil
+	}
+
+	schedule, err := r.h.scheduleBranch(ctx, id)
+	if status.Code(err) == codes.NotFound {
+		r.schedules[id] = ""
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	r.schedules[id] = schedule.GetBranchId()
+	return r.schedules[id], nil
+}
+
+func (r *branchResolver) lesson(ctx context.Context, id string) (string, error) {
+	scheduleID, ok := r.lessons[id]
+	if !ok {
+		lesson, err := r.h.grpcClient.LessonsService().GetById(ctx, &ls.LessonPrimaryKey{Id: id})
+		if status.Code(err) == codes.NotFound {
+			r.lessons[id] = ""
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		scheduleID = lesson.ScheduleId
+		r.lessons[id] = scheduleID
+	}
+	if scheduleID == "" {
+		return "", nil
+	}
+
+	return r.schedule(ctx, scheduleID)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+
+	"crmapi/api/models"
+	"crmapi/pkg"
+	"crmapi/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLogLevel godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/system/log-level [GET]
+// @Summary 	Get log level
+// @Description Returns the global log level and the per-namespace overrides
+// @Tags 		SYSTEM
+// @Produce  	json
+// @Success		200  {object} models.Response
+// @Failure 	401  {object} models.Problem
+// @Failure 	403  {object} models.Problem
+// @Failure 	501  {object} models.Problem
+func (h *handler) GetLogLevel(c *gin.Context) {
+	levels := logger.GetLevels(h.log)
+	if levels == nil {
+		handleProblem(c, h.log, http.StatusNotImplemented, ErrorCodeNotImplemented, "logger does not support runtime levels", nil)
+		return
+	}
+
+	handleResponse(c, h.log, "Log level got successfully", http.StatusOK, models.LogLevel{Level: levels.Level(), Namespaces: levels.Namespaces()})
+}
+
+// SetLogLevel godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/system/log-level [PUT]
+// @Summary 	Set log level
+// @Description Changes the global log level and/or per-namespace overrides without a restart, an empty namespace level removes its override
+// @Tags 		SYSTEM
+// @Accept  	json
+// @Produce  	json
+// @Param		level body models.LogLevel true "level"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	401  {object} models.Problem
+// @Failure 	403  {object} models.Problem
+// @Failure 	501  {object} models.Problem
+func (h *handler) SetLogLevel(c *gin.Context) {
+	levels := logger.GetLevels(h.log)
+	if levels == nil {
+		handleProblem(c, h.log, http.StatusNotImplemented, ErrorCodeNotImplemented, "logger does not support runtime levels", nil)
+		return
+	}
+
+	req := models.LogLevel{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	// validate everything first so that a bad entry does not leave a half applied change
+	var errs pkg.ValidationErrors
+	if req.Level != "" {
+		if _, err := logger.ParseLevel(req.Level); err != nil {
+			errs = append(errs, pkg.FieldError{Field: "level", Rule: "log_level", Message: err.Error()})
+		}
+	}
+	for namespace, level := range req.Namespaces {
+		if namespace == "" {
+			errs = append(errs, pkg.FieldError{Field: "namespaces", Rule: "required", Message: "namespace is empty"})
+			continue
+		}
+		if level == "" {
+			continue
+		}
+		if _, err := logger.ParseLevel(level); err != nil {
+			errs = append(errs, pkg.FieldError{Field: "namespaces." + namespace, Rule: "log_level", Message: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		handleError(c, h.log, errs, "log level is not valid")
+		return
+	}
+
+	if req.Level != "" {
+		_ = levels.SetLevel(req.Level)
+	}
+	for namespace, level := range req.Namespaces {
+		_ = levels.SetNamespaceLevel(namespace, level)
+	}
+
+	auth := getAuthInfo(c)
+	requestLogger(c, h.log).Warn("log level changed", logger.String("level", levels.Level()),
+		logger.Any("namespaces", levels.Namespaces()), logger.String("user_id", auth.UserID))
+
+	handleResponse(c, h.log, "Log level changed successfully", http.StatusOK, models.LogLevel{Level: levels.Level(), Namespaces: levels.Namespaces()})
+}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"crmapi/genproto/user_service/managers"
+	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateManager godoc
@@ -18,24 +21,33 @@ import (
 // @Produce  	json
 // @Param		manager body user_service.CreateManager true "manager"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateManager(c *gin.Context) {
 	manager := user_service.CreateManager{}
 
 	if err := c.ShouldBindJSON(&manager); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a manager")
+		handleError(c, h.log, err, "error while creating a manager")
 		return
 	}
 
-	_, err := h.grpcClient.Managers().Create(c.Request.Context(), &manager)
+	if err := pkg.Validate(&manager); err != nil {
+		handleError(c, h.log, err, "error while validating manager")
+		return
+	}
+
+	if !h.checkBranch(c, manager.BranchId) {
+		return
+	}
+
+	resp, err := h.grpcClient.Managers().Create(c.Request.Context(), &manager)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a manager")
+		handleError(c, h.log, err, "error while creating a manager")
 		return
 	}
-	handleGrpcErrWithDescription(c, h.log, err, "Manager created successfully")
+	handleResponse(c, h.log, "Manager created successfully", http.StatusCreated, resp)
 }
 
 // GetByIdManager godoc
@@ -47,15 +59,17 @@ func (h *handler) CreateManager(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,full_name,branch.name"
+// @Param		expand query string false "comma separated related records to embed: branch"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdManager(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating manager id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating manager id", err.Error())
 		return
 	}
 
@@ -66,11 +80,21 @@ func (h *handler) GetByIdManager(c *gin.Context) {
 	resp, err := h.grpcClient.Managers().GetById(c.Request.Context(), &manager)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting manager", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting manager")
+		return
+	}
+
+	if !h.checkBranch(c, resp.BranchId) {
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
 
-	handleResponse(c, h.log, "Manager got successfully", http.StatusOK, resp)
+	setETag(c, resp)
+	handleResponse(c, h.log, "Manager got successfully", http.StatusOK, data)
 }
 
 // UpdateManager godoc
@@ -83,26 +107,70 @@ func (h *handler) GetByIdManager(c *gin.Context) {
 // @Produce  	json
 // @Param		manager body user_service.UpdateManager true "manager"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateManager(c *gin.Context) {
 	manager := user_service.UpdateManager{}
 
 	if err := c.ShouldBindJSON(&manager); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if err := pkg.Validate(&manager); err != nil {
+		handleError(c, h.log, err, "error while validating manager")
+		return
+	}
+
+	unlock := h.locks.lock(manager.Id)
+	defer unlock()
+
+	if !h.checkBranch(c, manager.BranchId) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Managers().GetById(c.Request.Context(), &user_service.ManagerPrimaryKey{Id: manager.Id})
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.Managers().Update(c.Request.Context(), &manager)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating a manager", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating a manager")
 		return
 	}
 	handleResponse(c, h.log, "Manager updated successfully", http.StatusOK, resp)
 }
 
+// PatchManager godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/manager/{id} [PATCH]
+// @Summary 	Partially update a manager
+// @Description API for changing only the given fields of a manager, fields left out keep their stored value, the password too. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		MANAGERS
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the manager"
+// @Param		manager body user_service.UpdateManager true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	502  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchManager(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "manager",
+		update: &user_service.UpdateManager{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Managers().GetById(ctx, &user_service.ManagerPrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.Managers().Update(ctx, update.(*user_service.UpdateManager))
+		},
+	})
+}
+
 // DeleteManager godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/manager/{id} [DELETE]
@@ -113,24 +181,31 @@ func (h *handler) UpdateManager(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteManager(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating manager id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating manager id", err.Error())
 		return
 	}
 
 	manager := user_service.ManagerPrimaryKey{
 		Id: id,
 	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Managers().GetById(c.Request.Context(), &manager)
+	}) {
+		return
+	}
+
 	_, err := h.grpcClient.Managers().Delete(c.Request.Context(), &manager)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting a manager", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting a manager")
 		return
 	}
 	handleResponse(c, h.log, "Manager deleted successfully", http.StatusOK, "Manager deleted successfully")
@@ -145,38 +220,53 @@ func (h *handler) DeleteManager(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	is_active query boolean false "true or false"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,full_name"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,full_name,branch.name"
+// @Param    	expand query string false "comma separated related records to embed: branch"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllManagers(c *gin.Context) {
 	search := c.Query("search")
 
-	page, err := ParsePageQueryParam(c)
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
-		return
-	}
-	limit, err := ParseLimitQueryParam(c)
-
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
 
 	managers := user_service.GetListManagersRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
 		Search: search,
 	}
 
-	resp, err := h.grpcClient.Managers().GetAll(c.Request.Context(), &managers)
+	q, err := bindListQuery(c, &managers, &user_service.Manager{})
+	if err != nil {
+		handleError(c, h.log, err, "error while parsing filters")
+		return
+	}
+
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		managers.Offset, managers.Limit = offset, limit
+		return h.grpcClient.Managers().GetAll(ctx, &managers)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all managers", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all managers")
 		return
 	}
-	handleResponse(c, h.log, "Managers got successfully", http.StatusOK, resp)
+
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	handleListResponse(c, h.log, "Managers got successfully", data, p)
 }
\ No newline at end of file
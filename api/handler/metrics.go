@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"crmapi/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records count, latency and in-flight requests labeled by route template
+func (h *handler) Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			// keep the label set bounded when scanners hit random paths
+			route = "unmatched"
+		}
+
+		inFlight := metrics.HTTPInFlight.WithLabelValues(c.Request.Method, route)
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		start := time.Now()
+		c.Next()
+
+		status := strconv.Itoa(c.Writer.Status())
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, status).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route, status).Observe(time.Since(start).Seconds())
+	}
+}
@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"crmapi/api/models"
+	"crmapi/pkg"
+	"crmapi/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	pageParam   = "page"
+	limitParam  = "limit"
+	cursorParam = "cursor"
+)
+
+// pagination is the page a list request asks for. Offset mode uses ?page= and ?limit=,
+// cursor mode follows the opaque ?cursor= of a previous response and keeps its filters.
+// listRecords fills in where the page lies in the list.
+type pagination struct {
+	offset int64
+	limit  int64
+	query  string // fingerprint of the filters and sort the cursor was issued for
+	cursor *cursor
+
+	total       int64
+	size        int64    // records on the page
+	first, last []string // keyset of the first and last record, set when the list is in keyset order
+}
+
+// cursor is the content of an opaque cursor: the keyset of the record a page starts after
+// or ends before, in the order of the sort param followed by id. Pages of a cursor neither
+// skip nor repeat records when records are added or removed in between.
+type cursor struct {
+	After  []string `json:"a,omitempty"`
+	Before []string `json:"b,omitempty"`
+	Limit  int64    `json:"l"`
+	Query  string   `json:"q"`
+}
+
+// parsePagination reads the cursor or the page and limit of a list request.
+// Like before cursors, page=0 is the first page and limit=0 the default page size.
+func (h *handler) parsePagination(c *gin.Context) (pagination, error) {
+	p := pagination{limit: int64(h.cfg.DefaultPageSize), query: queryFingerprint(c)}
+
+	var errs pkg.ValidationErrors
+	if raw := c.Query(limitParam); raw != "" {
+		limit, err := strconv.ParseInt(raw, 10, 64)
+		switch {
+		case err != nil || limit < 0:
+			errs = append(errs, pkg.FieldError{Field: limitParam, Rule: "min", Message: "must be at least 1", Key: "min", Args: []interface{}{1}})
+		case limit > int64(h.cfg.MaxPageSize):
+			errs = append(errs, pkg.FieldError{Field: limitParam, Rule: "max", Message: fmt.Sprintf("must be at most %d", h.cfg.MaxPageSize), Key: "max", Args: []interface{}{h.cfg.MaxPageSize}})
+		case limit > 0:
+			p.limit = limit
+		}
+	}
+
+	if raw := c.Query(cursorParam); raw != "" {
+		cur, ok := decodeCursor(raw)
+		if !ok || (len(cur.After) == 0) == (len(cur.Before) == 0) || cur.Limit < 1 || cur.Limit > int64(h.cfg.MaxPageSize) {
+			errs = append(errs, pkg.FieldError{Field: cursorParam, Rule: "cursor", Message: "is not a valid cursor"})
+		} else if cur.Query != p.query {
+			errs = append(errs, pkg.FieldError{Field: cursorParam, Rule: "cursor", Message: "was issued for other filters, start again without a cursor"})
+		} else {
+			p.cursor = &cur
+			if c.Query(limitParam) == "" {
+				p.limit = cur.Limit
+			}
+		}
+	} else if raw := c.Query(pageParam); raw != "" {
+		page, err := strconv.ParseInt(raw, 10, 30)
+		switch {
+		case err != nil || page < 0:
+			errs = append(errs, pkg.FieldError{Field: pageParam, Rule: "min", Message: "must be at least 1", Key: "min", Args: []interface{}{1}})
+		case page > 0:
+			p.offset = (page - 1) * p.limit
+		}
+	}
+
+	if len(errs) > 0 {
+		return p, errs
+	}
+	return p, nil
+}
+
+// metadata describes the page and sets the Link header (RFC 8288) with the first, prev,
+// next and last pages. Pages in keyset order link their neighbours by cursor.
+func (p pagination) metadata(c *gin.Context) *models.Pagination {
+	meta := &models.Pagination{
+		Total:   p.total,
+		Page:    p.offset/p.limit + 1,
+		Limit:   p.limit,
+		Offset:  p.offset,
+		HasMore: p.size > 0 && p.offset+p.size < p.total,
+	}
+	keyset := len(p.first) > 0
+
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, p.pageLink(c, 0))}
+	if p.offset > 0 {
+		prev := fmt.Sprintf(`<%s>; rel="prev"`, p.pageLink(c, max(p.offset-p.limit, 0)))
+		if keyset {
+			meta.PrevCursor = p.encode(cursor{Before: p.first})
+			prev = fmt.Sprintf(`<%s>; rel="prev"`, p.cursorLink(c, meta.PrevCursor))
+		}
+		links = append(links, prev)
+	}
+	if meta.HasMore {
+		next := fmt.Sprintf(`<%s>; rel="next"`, p.pageLink(c, p.offset+p.limit))
+		if keyset {
+			meta.NextCursor = p.encode(cursor{After: p.last})
+			next = fmt.Sprintf(`<%s>; rel="next"`, p.cursorLink(c, meta.NextCursor))
+		}
+		links = append(links, next)
+	}
+	if p.total > 0 {
+		links = append(links, fmt.Sprintf(`<%s>; rel="last"`, p.pageLink(c, (p.total-1)/p.limit*p.limit)))
+	}
+	c.Header("Link", strings.Join(links, ", "))
+
+	return meta
+}
+
+// pageLink is the url of the page at offset
+func (p pagination) pageLink(c *gin.Context, offset int64) string {
+	query := c.Request.URL.Query()
+	query.Del(cursorParam)
+	query.Set(pageParam, strconv.FormatInt(offset/p.limit+1, 10))
+	query.Set(limitParam, strconv.FormatInt(p.limit, 10))
+
+	return (&url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}).String()
+}
+
+// cursorLink is the url of the page of a cursor
+func (p pagination) cursorLink(c *gin.Context, cur string) string {
+	query := c.Request.URL.Query()
+	query.Del(pageParam)
+	query.Del(limitParam)
+	query.Set(cursorParam, cur)
+
+	return (&url.URL{Path: c.Request.URL.Path, RawQuery: query.Encode()}).String()
+}
+
+func (p pagination) encode(cur cursor) string {
+	cur.Limit, cur.Query = p.limit, p.query
+	data, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (cursor, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return cursor{}, false
+	}
+
+	var cur cursor
+	if err := json.Unmarshal(data, &cur); err != nil {
+		return cursor{}, false
+	}
+	return cur, true
+}
+
+// queryFingerprint hashes the filters of a list request, everything but the paging
+// params and the shape of the response, which do not change the records of a page
+func queryFingerprint(c *gin.Context) string {
+	query := c.Request.URL.Query()
+	query.Del(cursorParam)
+	query.Del(pageParam)
+	query.Del(limitParam)
+	query.Del(fieldsParam)
+	query.Del(expandParam)
+
+	sum := sha256.Sum256([]byte(query.Encode()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// handleListResponse writes a page of a list together with its pagination metadata
+func handleListResponse(c *gin.Context, log logger.Logger, msg string, data interface{}, p pagination) {
+	writeResponse(c, log, msg, http.StatusOK, data, p.metadata(c))
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"crmapi/config"
+	st "crmapi/genproto/user_service/students"
+	"crmapi/pkg"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func testContext(target string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", target, nil)
+	return c
+}
+
+func testHandler() *handler {
+	return &handler{cfg: config.Config{DefaultPageSize: 10, MaxPageSize: 100}}
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	p := pagination{limit: 5, query: "q1"}
+	raw := p.encode(cursor{After: []string{"2024-01-01 10:00:00", "id-1"}})
+
+	cur, ok := decodeCursor(raw)
+	if !ok {
+		t.Fatal("decodeCursor rejected an encoded cursor")
+	}
+	want := cursor{After: []string{"2024-01-01 10:00:00", "id-1"}, Limit: 5, Query: "q1"}
+	if !reflect.DeepEqual(cur, want) {
+		t.Errorf("decodeCursor() = %+v, want %+v", cur, want)
+	}
+
+	for _, raw := range []string{"%%%", "bm90IGpzb24"} {
+		if _, ok := decodeCursor(raw); ok {
+			t.Errorf("decodeCursor(%q) accepted garbage", raw)
+		}
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	h := testHandler()
+	query := queryFingerprint(testContext("/x?sort=name"))
+	valid := pagination{limit: 3, query: query}.encode(cursor{After: []string{"a"}})
+	bothKeys := pagination{limit: 3, query: query}.encode(cursor{After: []string{"a"}, Before: []string{"b"}})
+	otherQuery := pagination{limit: 3, query: "other"}.encode(cursor{After: []string{"a"}})
+
+	tests := []struct {
+		name       string
+		target     string
+		offset     int64
+		limit      int64
+		cursor     bool
+		errorField string
+	}{
+		{"defaults", "/x", 0, 10, false, ""},
+		{"page and limit", "/x?page=3&limit=20", 40, 20, false, ""},
+		{"page and limit 0 keep the defaults", "/x?page=0&limit=0", 0, 10, false, ""},
+		{"negative page", "/x?page=-1", 0, 10, false, "page"},
+		{"limit over the maximum", "/x?limit=101", 0, 10, false, "limit"},
+		{"cursor keeps its limit", "/x?sort=name&cursor=" + valid, 0, 3, true, ""},
+		{"cursor with both keys", "/x?sort=name&cursor=" + bothKeys, 0, 10, false, "cursor"},
+		{"cursor of other filters", "/x?sort=name&cursor=" + otherQuery, 0, 10, false, "cursor"},
+		{"garbage cursor", "/x?cursor=abc", 0, 10, false, "cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := h.parsePagination(testContext(tt.target))
+			if tt.errorField != "" {
+				errs, ok := err.(pkg.ValidationErrors)
+				if !ok || len(errs) != 1 || errs[0].Field != tt.errorField {
+					t.Fatalf("parsePagination() error = %v, want one error of %s", err, tt.errorField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.offset != tt.offset || p.limit != tt.limit || (p.cursor != nil) != tt.cursor {
+				t.Errorf("parsePagination() = offset %d limit %d cursor %v, want %d %d %v", p.offset, p.limit, p.cursor != nil, tt.offset, tt.limit, tt.cursor)
+			}
+		})
+	}
+}
+
+// TestKeysetPaging walks a list by cursor while records are added in front of and
+// behind the current page, no record may be skipped or repeated
+func TestKeysetPaging(t *testing.T) {
+	desc := (&st.Student{}).ProtoReflect().Descriptor()
+	order := keysetOrder(desc, nil)
+	if len(order) != 2 || order[0].field.Name() != "created_at" || order[1].field.Name() != "id" {
+		t.Fatalf("keysetOrder() = %v, want created_at, id", order)
+	}
+
+	var records []protoreflect.Value
+	add := func(day, id int) {
+		s := &st.Student{Id: fmt.Sprintf("id-%03d", id), CreatedAt: fmt.Sprintf("2024-01-%02d 10:00:00", day)}
+		records = append(records, protoreflect.ValueOfMessage(s.ProtoReflect()))
+		sortRecords(records, order)
+	}
+	for i := 0; i < 10; i++ {
+		add(i%3+1, i)
+	}
+
+	seen := map[string]bool{}
+	var after []string
+	for page := 0; ; page++ {
+		start := 0
+		if after != nil {
+			for start < len(records) && compareKeyset(records[start].Message(), after, order) <= 0 {
+				start++
+			}
+		}
+		end := min(start+4, len(records))
+		for _, r := range records[start:end] {
+			id := r.Message().Interface().(*st.Student).Id
+			if seen[id] {
+				t.Fatalf("record %s repeated on page %d", id, page)
+			}
+			seen[id] = true
+		}
+		if end == len(records) {
+			break
+		}
+		after = keyset(records[end-1].Message(), order)
+
+		// a record before the cursor and one after it
+		add(1, 100+page)
+		add(28, 200+page)
+	}
+
+	for _, r := range records {
+		id := r.Message().Interface().(*st.Student).Id
+		if !seen[id] && !strings.HasPrefix(id, "id-1") {
+			t.Errorf("record %s was skipped", id)
+		}
+	}
+}
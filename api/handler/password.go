@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"crmapi/api/models"
+	"crmapi/pkg"
+	"crmapi/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChangePassword godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/me/password [POST]
+// @Summary 	Change own password
+// @Description API for changing the password of the logged in user
+// @Tags 		AUTH
+// @Accept  	json
+// @Produce  	json
+// @Param		password body models.ChangePasswordRequest true "password"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	401  {object} models.Problem
+// @Failure 	403  {object} models.Problem "old password is wrong"
+// @Failure 	429  {object} models.Problem "too many attempts, see Retry-After"
+// @Failure 	500  {object} models.Problem
+func (h *handler) ChangePassword(c *gin.Context) {
+	req := models.ChangePasswordRequest{}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if req.NewPassword != req.ConfirmPassword {
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodePasswordsNotEqual, "new password and confirmation are not equal", nil)
+		return
+	}
+
+	if err := pkg.ValidatePassword(req.NewPassword); err != nil {
+		handleError(c, h.log, err, "password is not valid")
+		return
+	}
+
+	auth := getAuthInfo(c)
+
+	// the old password can be guessed with a stolen token, limit the attempts per user
+	if !h.passwords.Allow(auth.UserID) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(h.cfg.PasswordChangeWindow.Seconds()))))
+		handleProblem(c, h.log, http.StatusTooManyRequests, ErrorCodeTooManyRequests, "too many password change attempts, try again later", nil)
+		return
+	}
+
+	user, err := h.getAccount(c.Request.Context(), auth.UserRole, auth.UserID)
+	if err != nil {
+		handleError(c, h.log, err, "error while getting user")
+		return
+	}
+
+	if !security.CompareHashAndPassword(user.Password, req.OldPassword) {
+		handleProblem(c, h.log, http.StatusForbidden, ErrorCodeForbidden, "old password is wrong", nil)
+		return
+	}
+
+	if err := h.setAccountPassword(c.Request.Context(), auth.UserRole, auth.UserID, req.NewPassword); err != nil {
+		handleError(c, h.log, err, "error while updating password")
+		return
+	}
+	h.passwords.Reset(auth.UserID)
+
+	handleResponse(c, h.log, "Password changed successfully", http.StatusOK, "Password changed successfully")
+}
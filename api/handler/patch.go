@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"crmapi/pkg"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// patchTarget connects the PATCH route of one resource to its backend
+type patchTarget struct {
+	name   string        // resource name for messages, e.g. "student"
+	update proto.Message // empty Update* message the merged record is written into
+	get    func(ctx context.Context, id string) (proto.Message, error)
+	save   func(ctx context.Context, update proto.Message) (proto.Message, error)
+	// branch resolves the branch of a stored or merged record without a branch_id, e.g. of a lesson by its schedule
+	branch func(ctx context.Context, record proto.Message) (branchRecord, error)
+}
+
+// writeOnlyFields hold a value that is stored but not meant to be read, the password hash.
+// Lists cannot be sorted by them. A patch that leaves them out sends the stored hash back
+// unchanged, the backends keep a value that is already a bcrypt hash. patch checks that
+// the saved record still has the same hash.
+var writeOnlyFields = map[protoreflect.Name]bool{
+	"password": true,
+}
+
+// recordLocks serializes the writes of one record that go through this gateway instance, so
+// that the If-Match check of a PATCH and its update cannot interleave with another PATCH,
+// PUT or password change. Instances do not share locks: the backends have no conditional
+// update, and writes through other instances can still land between the check and the update.
+type recordLocks struct {
+	mu    sync.Mutex
+	locks map[string]*recordLock
+}
+
+type recordLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newRecordLocks() *recordLocks {
+	return &recordLocks{locks: map[string]*recordLock{}}
+}
+
+// lock locks the record with the id and returns its unlock
+func (l *recordLocks) lock(id string) func() {
+	l.mu.Lock()
+	rl, ok := l.locks[id]
+	if !ok {
+		rl = &recordLock{}
+		l.locks[id] = rl
+	}
+	rl.refs++
+	l.mu.Unlock()
+
+	rl.Lock()
+	return func() {
+		rl.Unlock()
+
+		l.mu.Lock()
+		if rl.refs--; rl.refs == 0 {
+			delete(l.locks, id)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// patch applies a partial update: the body holds only the fields to change, they are
+// merged into the current record, validated on their own and the merged Update* message
+// is sent to the backend. Fields left out, the password too, keep their stored value. An
+// If-Match header makes the update conditional on the ETag returned by GET, the check and
+// the update hold the lock of the record, see recordLocks.
+func (h *handler) patch(c *gin.Context, t patchTarget) {
+	id := c.Param("id")
+	if err := uuid.Validate(id); err != nil {
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating "+t.name+" id", err.Error())
+		return
+	}
+
+	body := map[string]json.RawMessage{}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+	if len(body) == 0 {
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidJSON, "patch has no fields to change", nil)
+		return
+	}
+
+	changed, err := patchFields(t.update, body)
+	if err != nil {
+		handleError(c, h.log, err, "error while reading "+t.name+" patch")
+		return
+	}
+
+	unlock := h.locks.lock(id)
+	defer unlock()
+
+	current, err := t.get(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, h.log, err, "error while getting "+t.name)
+		return
+	}
+	if !h.checkPatchBranch(c, t, current) {
+		return
+	}
+
+	if !matchesETag(c.GetHeader("If-Match"), entityTag(current)) {
+		handleProblem(c, h.log, http.StatusPreconditionFailed, ErrorCodeFailedPrecondition, t.name+" was changed since it was read, get it again and retry", nil)
+		return
+	}
+
+	update := t.update
+	mergeRecord(current, update)
+
+	patchJSON, _ := json.Marshal(body)
+	if err := json.Unmarshal(patchJSON, update); err != nil {
+		handleError(c, h.log, err, "error while reading "+t.name+" patch")
+		return
+	}
+
+	if err := pkg.ValidateFields(update, changed); err != nil {
+		handleError(c, h.log, err, "error while validating "+t.name)
+		return
+	}
+	if !h.checkPatchBranch(c, t, update) {
+		return
+	}
+
+	resp, err := t.save(c.Request.Context(), update)
+	if err != nil {
+		handleError(c, h.log, err, "error while updating "+t.name)
+		return
+	}
+	if field, ok := keptWriteOnly(current, resp, body); !ok {
+		handleProblem(c, h.log, http.StatusBadGateway, ErrorCodeInternal, t.name+" was updated but its "+field+" changed, set it again", "backend did not keep the stored "+field)
+		return
+	}
+
+	setETag(c, resp)
+	handleResponse(c, h.log, strings.ToUpper(t.name[:1])+t.name[1:]+" updated successfully", http.StatusOK, resp)
+}
+
+// checkPatchBranch checks that a stored or merged record of t is in the caller's branch
+func (h *handler) checkPatchBranch(c *gin.Context, t patchTarget, record proto.Message) bool {
+	if t.branch != nil {
+		return h.checkRecordBranch(c, func() (branchRecord, error) {
+			return t.branch(c.Request.Context(), record)
+		})
+	}
+	if r, ok := record.(branchRecord); ok {
+		return h.checkBranch(c, r.GetBranchId())
+	}
+
+	return true
+}
+
+// patchFields returns the field names of a patch, rejecting the id and unknown fields
+func patchFields(update proto.Message, body map[string]json.RawMessage) ([]string, error) {
+	fields := update.ProtoReflect().Descriptor().Fields()
+
+	var errs pkg.ValidationErrors
+	changed := make([]string, 0, len(body))
+	for key := range body {
+		if key == "id" || fields.ByName(protoreflect.Name(key)) == nil {
+			errs = append(errs, pkg.FieldError{Field: key, Rule: "unknown", Message: "cannot be changed"})
+			continue
+		}
+		changed = append(changed, key)
+	}
+	sort.Strings(changed)
+
+	if len(errs) > 0 {
+		sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+		return nil, errs
+	}
+	return changed, nil
+}
+
+// keptWriteOnly reports whether the saved record still has the stored value of every
+// write-only field the patch left out, and the first field that changed
+func keptWriteOnly(current, saved proto.Message, body map[string]json.RawMessage) (string, bool) {
+	before, after := current.ProtoReflect(), saved.ProtoReflect()
+	fields := before.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if _, ok := body[string(fd.Name())]; ok || !writeOnlyFields[fd.Name()] {
+			continue
+		}
+		to := after.Descriptor().Fields().ByName(fd.Name())
+		if to == nil || !after.Has(to) {
+			continue
+		}
+		if !before.Get(fd).Equal(after.Get(to)) {
+			return string(fd.Name()), false
+		}
+	}
+	return "", true
+}
+
+// mergeRecord copies every field of the stored record into the fields of the update
+// message with the same name and type
+func mergeRecord(record, update proto.Message) {
+	src, dst := record.ProtoReflect(), update.ProtoReflect()
+
+	fields := dst.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		from := src.Descriptor().Fields().ByName(fd.Name())
+		if from == nil || !sameType(fd, from) || !src.Has(from) {
+			continue
+		}
+		dst.Set(fd, src.Get(from))
+	}
+}
+
+func sameType(a, b protoreflect.FieldDescriptor) bool {
+	if a.Kind() != b.Kind() || a.Cardinality() != b.Cardinality() {
+		return false
+	}
+	switch a.Kind() {
+	case protoreflect.EnumKind:
+		return a.Enum().FullName() == b.Enum().FullName()
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return a.Message().FullName() == b.Message().FullName()
+	}
+	return true
+}
+
+// entityTag is a strong ETag of a backend record, the hash of its deterministic encoding
+func entityTag(m proto.Message) string {
+	data, _ := proto.MarshalOptions{Deterministic: true}.Marshal(m)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// setETag lets clients send the version they read back in If-Match
+func setETag(c *gin.Context, m proto.Message) {
+	c.Header("ETag", entityTag(m))
+}
+
+// matchesETag implements the strong comparison of If-Match, an absent header always matches
+func matchesETag(ifMatch, etag string) bool {
+	if ifMatch == "" {
+		return true
+	}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		if tag = strings.TrimSpace(tag); tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	st "crmapi/genproto/user_service/students"
+)
+
+func TestMergeRecordKeepsPassword(t *testing.T) {
+	stored := &st.Student{Id: "s1", FullName: "Ali Valiyev", Password: "$2a$10$hash", GroupId: "g1"}
+	update := &st.UpdateStudent{}
+
+	mergeRecord(stored, update)
+	if update.Password != stored.Password || update.FullName != stored.FullName || update.GroupId != "g1" {
+		t.Errorf("mergeRecord() = %+v, want the stored fields", update)
+	}
+}
+
+func TestKeptWriteOnly(t *testing.T) {
+	stored := &st.Student{Id: "s1", Password: "$2a$10$hash"}
+
+	tests := []struct {
+		name  string
+		saved *st.Student
+		body  string
+		want  bool
+	}{
+		{"hash kept", &st.Student{Id: "s1", Password: "$2a$10$hash"}, `{"group_id":"g2"}`, true},
+		{"hash not returned", &st.Student{Id: "s1"}, `{"group_id":"g2"}`, true},
+		{"hash changed", &st.Student{Id: "s1", Password: "$2a$10$other"}, `{"group_id":"g2"}`, false},
+		{"password patched", &st.Student{Id: "s1", Password: "$2a$10$other"}, `{"password":"Secret123"}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := map[string]json.RawMessage{}
+			if err := json.Unmarshal([]byte(tt.body), &body); err != nil {
+				t.Fatal(err)
+			}
+			if field, ok := keptWriteOnly(stored, tt.saved, body); ok != tt.want {
+				t.Errorf("keptWriteOnly() = %q, %v, want %v", field, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordLocks(t *testing.T) {
+	locks := newRecordLocks()
+
+	var (
+		wg      sync.WaitGroup
+		inside  int
+		overlap bool
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.lock("r1")
+			defer unlock()
+
+			inside++
+			if inside != 1 {
+				overlap = true
+			}
+			inside--
+		}()
+	}
+	wg.Wait()
+
+	if overlap {
+		t.Error("two writers of one record held its lock at the same time")
+	}
+	if len(locks.locks) != 0 {
+		t.Errorf("%d locks left after every writer unlocked", len(locks.locks))
+	}
+
+	unlockA := locks.lock("a")
+	unlockB := locks.lock("b") // must not wait for a
+	unlockB()
+	unlockA()
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RBACMiddleware checks the caller's role against the policy table for the matched route.
+// It must run after AuthMiddleware.
+func (h *handler) RBACMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			c.Next()
+			return
+		}
+
+		auth := getAuthInfo(c)
+
+		decision := h.policy.Check(c.Request.Method, route, auth.UserRole)
+		if !decision.Allowed {
+			handleProblem(c, h.log, http.StatusForbidden, ErrorCodeForbidden, "role is not allowed to call "+c.Request.Method+" "+route, nil)
+			return
+		}
+
+		if decision.SelfOnly && c.Param("id") != auth.UserID {
+			handleProblem(c, h.log, http.StatusForbidden, ErrorCodeForbidden, "role may access only its own record", nil)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"crmapi/pkg/logger"
+	"crmapi/pkg/requestid"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const ctxRequestID = "request_id"
+
+// RequestID reuses the X-Request-ID sent by the client or generates one, stores it in
+// the request context so it reaches the backends and echoes it in the response
+func (h *handler) RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		c.Set(ctxRequestID, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Header(requestid.Header, id)
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("request.id", id))
+
+		c.Next()
+	}
+}
+
+// requestID returns the id of the current request, creating one for routes served without the middleware
+func requestID(c *gin.Context) string {
+	if id := c.GetString(ctxRequestID); id != "" {
+		return id
+	}
+
+	id := requestid.New()
+	c.Set(ctxRequestID, id)
+	c.Header(requestid.Header, id)
+
+	return id
+}
+
+// requestLogger adds the request id to every line written by l
+func requestLogger(c *gin.Context, l logger.Logger) logger.Logger {
+	return logger.WithFields(l, logger.String("request_id", requestID(c)))
+}
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"crmapi/genproto/schedule_service/schedules"
+	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateSchedule godoc
@@ -18,24 +21,33 @@ import (
 // @Produce  	json
 // @Param		schedule body schedule_service.CreateSchedule true "schedule"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateSchedule(c *gin.Context) {
 	schedule := schedule_service.CreateSchedule{}
 
 	if err := c.ShouldBindJSON(&schedule); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a schedule")
+		handleError(c, h.log, err, "error while creating a schedule")
 		return
 	}
 
-	_, err := h.grpcClient.Schedules().Create(c.Request.Context(), &schedule)
+	if err := pkg.Validate(&schedule); err != nil {
+		handleError(c, h.log, err, "error while validating schedule")
+		return
+	}
+
+	if !h.checkBranch(c, schedule.BranchId) {
+		return
+	}
+
+	resp, err := h.grpcClient.Schedules().Create(c.Request.Context(), &schedule)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a schedule")
+		handleError(c, h.log, err, "error while creating a schedule")
 		return
 	}
-	handleGrpcErrWithDescription(c, h.log, err, "Schedule created successfully")
+	handleResponse(c, h.log, "Schedule created successfully", http.StatusCreated, resp)
 }
 
 // GetByIdSchedule godoc
@@ -47,15 +59,17 @@ func (h *handler) CreateSchedule(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,start_time,group.name"
+// @Param		expand query string false "comma separated related records to embed: branch, group, teacher, support_teacher"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdSchedule(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating schedule id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating schedule id", err.Error())
 		return
 	}
 
@@ -66,11 +80,21 @@ func (h *handler) GetByIdSchedule(c *gin.Context) {
 	resp, err := h.grpcClient.Schedules().GetById(c.Request.Context(), &schedule)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting schedule", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting schedule")
 		return
 	}
 
-	handleResponse(c, h.log, "Schedule got successfully", http.StatusOK, resp)
+	if !h.checkBranch(c, resp.BranchId) {
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	setETag(c, resp)
+	handleResponse(c, h.log, "Schedule got successfully", http.StatusOK, data)
 }
 
 // UpdateSchedule godoc
@@ -83,26 +107,69 @@ func (h *handler) GetByIdSchedule(c *gin.Context) {
 // @Produce  	json
 // @Param		schedule body schedule_service.UpdateSchedule true "schedule"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateSchedule(c *gin.Context) {
 	schedule := schedule_service.UpdateSchedule{}
 
 	if err := c.ShouldBindJSON(&schedule); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if err := pkg.Validate(&schedule); err != nil {
+		handleError(c, h.log, err, "error while validating schedule")
+		return
+	}
+
+	unlock := h.locks.lock(schedule.Id)
+	defer unlock()
+
+	if !h.checkBranch(c, schedule.BranchId) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Schedules().GetById(c.Request.Context(), &schedule_service.SchedulePrimaryKey{Id: schedule.Id})
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.Schedules().Update(c.Request.Context(), &schedule)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating a schedule", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating a schedule")
 		return
 	}
 	handleResponse(c, h.log, "Schedule updated successfully", http.StatusOK, resp)
 }
 
+// PatchSchedule godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/schedule/{id} [PATCH]
+// @Summary 	Partially update a schedule
+// @Description API for changing only the given fields of a schedule, fields left out keep their stored value. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		SCHEDULES
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the schedule"
+// @Param		schedule body schedule_service.UpdateSchedule true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchSchedule(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "schedule",
+		update: &schedule_service.UpdateSchedule{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Schedules().GetById(ctx, &schedule_service.SchedulePrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.Schedules().Update(ctx, update.(*schedule_service.UpdateSchedule))
+		},
+	})
+}
+
 // DeleteSchedule godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/schedule/{id} [DELETE]
@@ -113,24 +180,31 @@ func (h *handler) UpdateSchedule(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteSchedule(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating schedule id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating schedule id", err.Error())
 		return
 	}
 
 	schedule := schedule_service.SchedulePrimaryKey{
 		Id: id,
 	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Schedules().GetById(c.Request.Context(), &schedule)
+	}) {
+		return
+	}
+
 	_, err := h.grpcClient.Schedules().Delete(c.Request.Context(), &schedule)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting a schedule", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting a schedule")
 		return
 	}
 	handleResponse(c, h.log, "Schedule deleted successfully", http.StatusOK, "Schedule deleted successfully")
@@ -145,36 +219,54 @@ func (h *handler) DeleteSchedule(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	group_id query string false "group id"
+// @Param    	teacher_id query string false "teacher id"
+// @Param    	support_teacher_id query string false "support teacher id"
+// @Param    	from query string false "start_time at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	to query string false "start_time at or before, a date includes the whole day"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,id"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,start_time,group.name"
+// @Param    	expand query string false "comma separated related records to embed: branch, group, teacher, support_teacher"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllSchedules(c *gin.Context) {
 
-	page, err := ParsePageQueryParam(c)
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
-	limit, err := ParseLimitQueryParam(c)
 
+	schedules := schedule_service.GetListScheduleRequest{}
+
+	q, err := bindListQuery(c, &schedules, &schedule_service.Schedule{})
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing filters")
 		return
 	}
 
-	schedules := schedule_service.GetListScheduleRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
-	}
-
-	resp, err := h.grpcClient.Schedules().GetAll(c.Request.Context(), &schedules)
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		schedules.Offset, schedules.Limit = offset, limit
+		return h.grpcClient.Schedules().GetAll(ctx, &schedules)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all schedules", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all schedules")
 		return
 	}
-	handleResponse(c, h.log, "Schedules got successfully", http.StatusOK, resp)
+
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	handleListResponse(c, h.log, "Schedules got successfully", data, p)
 }
@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"context"
 	user_service "crmapi/genproto/user_service/students"
 	"crmapi/pkg"
+	"crmapi/pkg/metrics"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateStudent godoc
@@ -19,38 +22,33 @@ import (
 // @Produce  	json
 // @Param		student body user_service.CreateStudent true "student"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateStudent(c *gin.Context) {
 	student := user_service.CreateStudent{}
 
 	if err := c.ShouldBindJSON(&student); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a student")
+		handleError(c, h.log, err, "error while creating a student")
 		return
 	}
 
-	if err := pkg.ValidateFullName(student.FullName); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "full name is not valid")
+	if err := pkg.Validate(&student); err != nil {
+		handleError(c, h.log, err, "error while validating student")
 		return
 	}
 
-	if err := pkg.ValidatePassword(student.Password); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "password is not valid")
-		return
-	}
-
-	if err := pkg.ValidatePhone(student.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "phone number starts with +998...")
+	if !h.checkBranch(c, student.BranchId) {
 		return
 	}
 
 	resp, err := h.grpcClient.Students().Create(c.Request.Context(), &student)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a student")
+		handleError(c, h.log, err, "error while creating a student")
 		return
 	}
+	metrics.StudentsCreated.Inc()
 	handleResponse(c, h.log, "Student created successfully", http.StatusCreated, resp)
 }
 
@@ -63,15 +61,17 @@ func (h *handler) CreateStudent(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,full_name,group.name"
+// @Param		expand query string false "comma separated related records to embed: branch, group"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdStudent(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating student id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating student id", err.Error())
 		return
 	}
 
@@ -82,11 +82,21 @@ func (h *handler) GetByIdStudent(c *gin.Context) {
 	resp, err := h.grpcClient.Students().GetById(c.Request.Context(), &student)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting student", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting student")
+		return
+	}
+
+	if !h.checkBranch(c, resp.BranchId) {
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
 
-	handleResponse(c, h.log, "Student got successfully", http.StatusOK, resp)
+	setETag(c, resp)
+	handleResponse(c, h.log, "Student got successfully", http.StatusOK, data)
 }
 
 // UpdateStudent godoc
@@ -99,41 +109,70 @@ func (h *handler) GetByIdStudent(c *gin.Context) {
 // @Produce  	json
 // @Param		student body user_service.UpdateStudent true "student"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateStudent(c *gin.Context) {
 	student := user_service.UpdateStudent{}
 
 	if err := c.ShouldBindJSON(&student); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
 		return
 	}
 
-	if err := pkg.ValidateFullName(student.FullName); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "full name is not valid")
+	if err := pkg.Validate(&student); err != nil {
+		handleError(c, h.log, err, "error while validating student")
 		return
 	}
 
-	if err := pkg.ValidatePassword(student.Password); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "password is not valid")
-		return
-	}
+	unlock := h.locks.lock(student.Id)
+	defer unlock()
 
-	if err := pkg.ValidatePhone(student.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "phone number starts with +998...")
+	if !h.checkBranch(c, student.BranchId) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Students().GetById(c.Request.Context(), &user_service.StudentPrimaryKey{Id: student.Id})
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.Students().Update(c.Request.Context(), &student)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating a student", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating a student")
 		return
 	}
 	handleResponse(c, h.log, "Student updated successfully", http.StatusOK, resp)
 }
 
+// PatchStudent godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/student/{id} [PATCH]
+// @Summary 	Partially update a student
+// @Description API for changing only the given fields of a student, fields left out keep their stored value, the password too. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		STUDENTS
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the student"
+// @Param		student body user_service.UpdateStudent true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	502  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchStudent(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "student",
+		update: &user_service.UpdateStudent{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Students().GetById(ctx, &user_service.StudentPrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.Students().Update(ctx, update.(*user_service.UpdateStudent))
+		},
+	})
+}
+
 // DeleteStudent godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/student/{id} [DELETE]
@@ -144,24 +183,31 @@ func (h *handler) UpdateStudent(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteStudent(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating manager id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating manager id", err.Error())
 		return
 	}
 
 	student := user_service.StudentPrimaryKey{
 		Id: id,
 	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Students().GetById(c.Request.Context(), &student)
+	}) {
+		return
+	}
+
 	_, err := h.grpcClient.Students().Delete(c.Request.Context(), &student)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting a student", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting a student")
 		return
 	}
 	handleResponse(c, h.log, "Student deleted successfully", http.StatusOK, "Student deleted successfully")
@@ -176,38 +222,55 @@ func (h *handler) DeleteStudent(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	group_id query string false "group id"
+// @Param    	is_active query boolean false "true or false"
+// @Param    	has_debt query boolean false "true for students who paid less than total_sum"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,full_name"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,full_name,group.name"
+// @Param    	expand query string false "comma separated related records to embed: branch, group"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllStudents(c *gin.Context) {
 	search := c.Query("search")
 
-	page, err := ParsePageQueryParam(c)
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
-		return
-	}
-	limit, err := ParseLimitQueryParam(c)
-
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
 
 	students := user_service.GetListStudentsRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
 		Search: search,
 	}
 
-	resp, err := h.grpcClient.Students().GetAll(c.Request.Context(), &students)
+	q, err := bindListQuery(c, &students, &user_service.Student{})
+	if err != nil {
+		handleError(c, h.log, err, "error while parsing filters")
+		return
+	}
+
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		students.Offset, students.Limit = offset, limit
+		return h.grpcClient.Students().GetAll(ctx, &students)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all students", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all students")
 		return
 	}
-	handleResponse(c, h.log, "Students got successfully", http.StatusOK, resp)
+
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	handleListResponse(c, h.log, "Students got successfully", data, p)
 }
\ No newline at end of file
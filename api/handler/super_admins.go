@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"context"
 	user_service "crmapi/genproto/user_service/super_admins"
 	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateSuperAdmin godoc
@@ -19,39 +21,29 @@ import (
 // @Produce  	json
 // @Param		super-admin body user_service.CreateSuperAdmin true "super-admin"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateSuperAdmin(c *gin.Context) {
 	superAdmin := user_service.CreateSuperAdmin{}
 
 	if err := c.ShouldBindJSON(&superAdmin); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating an order")
+		handleError(c, h.log, err, "error while creating an order")
 		return
 	}
 
-	if err := pkg.ValidateFullName(superAdmin.FullName); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "full name is not valid")
-		return
-	}
-
-	if err := pkg.ValidatePassword(superAdmin.Password); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "password is not valid")
-		return
-	}
-
-	if err := pkg.ValidatePhone(superAdmin.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "phone number starts with +998...")
+	if err := pkg.Validate(&superAdmin); err != nil {
+		handleError(c, h.log, err, "error while validating super admin")
 		return
 	}
 
 	resp, err := h.grpcClient.SuperAdmins().Create(c.Request.Context(), &superAdmin)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating superAdmin")
+		handleError(c, h.log, err, "error while creating superAdmin")
 		return
 	}
-	c.JSON(http.StatusCreated, resp)
+	handleResponse(c, h.log, "Super admin created successfully", http.StatusCreated, resp)
 }
 
 // GetByIdSuperAdmin godoc
@@ -63,15 +55,16 @@ func (h *handler) CreateSuperAdmin(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,full_name"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdSuperAdmin(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating super-admin", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating super-admin", err.Error())
 		return
 	}
 
@@ -82,11 +75,17 @@ func (h *handler) GetByIdSuperAdmin(c *gin.Context) {
 	resp, err := h.grpcClient.SuperAdmins().GetById(c.Request.Context(), &superAdmin)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting superAdmin", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting superAdmin")
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
 
-	handleResponse(c, h.log, "superAdmin got successfully", http.StatusOK, resp)
+	setETag(c, resp)
+	handleResponse(c, h.log, "superAdmin got successfully", http.StatusOK, data)
 }
 
 // UpdateSuperAdmin godoc
@@ -99,41 +98,64 @@ func (h *handler) GetByIdSuperAdmin(c *gin.Context) {
 // @Produce  	json
 // @Param		super-admin body user_service.UpdateSuperAdmin true "super-admin"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateSuperAdmin(c *gin.Context) {
 	superAdmin := user_service.UpdateSuperAdmin{}
 
 	if err := c.ShouldBindJSON(&superAdmin); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
 		return
 	}
 
-	if err := pkg.ValidateFullName(superAdmin.FullName); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "full name is not valid")
+	if err := pkg.Validate(&superAdmin); err != nil {
+		handleError(c, h.log, err, "error while validating super admin")
 		return
 	}
 
-	if err := pkg.ValidatePassword(superAdmin.Password); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "password is not valid")
-		return
-	}
-
-	if err := pkg.ValidatePhone(superAdmin.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "phone number starts with +998...")
-		return
-	}
+	unlock := h.locks.lock(superAdmin.Id)
+	defer unlock()
 
 	resp, err := h.grpcClient.SuperAdmins().Update(c.Request.Context(), &superAdmin)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating superAdmin", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating superAdmin")
 		return
 	}
 	handleResponse(c, h.log, "superAdmin updated successfully", http.StatusOK, resp)
 }
 
+// PatchSuperAdmin godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/super-admin/{id} [PATCH]
+// @Summary 	Partially update a super admin
+// @Description API for changing only the given fields of a super admin, fields left out keep their stored value, the password too. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		SUPER-ADMINS
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the super admin"
+// @Param		super_admin body user_service.UpdateSuperAdmin true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	502  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchSuperAdmin(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "super admin",
+		update: &user_service.UpdateSuperAdmin{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.SuperAdmins().GetById(ctx, &user_service.SuperAdminPrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.SuperAdmins().Update(ctx, update.(*user_service.UpdateSuperAdmin))
+		},
+	})
+}
+
 // DeleteOrder godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/super-admin/{id} [DELETE]
@@ -144,14 +166,14 @@ func (h *handler) UpdateSuperAdmin(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteSuperAdmin(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating super-admin id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating super-admin id", err.Error())
 		return
 	}
 
@@ -162,7 +184,7 @@ func (h *handler) DeleteSuperAdmin(c *gin.Context) {
 	_, err := h.grpcClient.SuperAdmins().Delete(c.Request.Context(), &superAdminId)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting an superAdmin", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting an superAdmin")
 		return
 	}
 	handleResponse(c, h.log, "Super admin deleted successfully", http.StatusOK, "Super admin deleted successfully")
@@ -177,39 +199,50 @@ func (h *handler) DeleteSuperAdmin(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,full_name"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,full_name"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllSuperAdmin(c *gin.Context) {
 	search := c.Query("search")
 
-	page, err := ParsePageQueryParam(c)
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
-		return
-	}
-	limit, err := ParseLimitQueryParam(c)
-
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
 
 	superAdmins := user_service.GetListSuperAdminRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
 		Search: search,
 	}
 
-	resp, err := h.grpcClient.SuperAdmins().GetAll(c.Request.Context(), &superAdmins)
+	q, err := bindListQuery(c, &superAdmins, &user_service.SuperAdmin{})
+	if err != nil {
+		handleError(c, h.log, err, "error while parsing filters")
+		return
+	}
+
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		superAdmins.Offset, superAdmins.Limit = offset, limit
+		return h.grpcClient.SuperAdmins().GetAll(ctx, &superAdmins)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all superAdmins", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all superAdmins")
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
 
-	handleResponse(c, h.log, "superAdmins got successfully", http.StatusOK, resp)
+	handleListResponse(c, h.log, "superAdmins got successfully", data, p)
 }
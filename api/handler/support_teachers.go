@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"crmapi/genproto/user_service/support_teachers"
+	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateSupportTeacher godoc
@@ -18,24 +21,33 @@ import (
 // @Produce  	json
 // @Param		support-teacher body user_service.CreateSupportTeacher true "support-teacher"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateSupportTeacher(c *gin.Context) {
 	supportTeacher := user_service.CreateSupportTeacher{}
 
 	if err := c.ShouldBindJSON(&supportTeacher); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a supportTeacher")
+		handleError(c, h.log, err, "error while creating a supportTeacher")
 		return
 	}
 
-	_, err := h.grpcClient.SupportTeacher().Create(c.Request.Context(), &supportTeacher)
+	if err := pkg.Validate(&supportTeacher); err != nil {
+		handleError(c, h.log, err, "error while validating support teacher")
+		return
+	}
+
+	if !h.checkBranch(c, supportTeacher.BranchId) {
+		return
+	}
+
+	resp, err := h.grpcClient.SupportTeacher().Create(c.Request.Context(), &supportTeacher)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a support teacher")
+		handleError(c, h.log, err, "error while creating a support teacher")
 		return
 	}
-	handleGrpcErrWithDescription(c, h.log, err, "Support teacher created successfully")
+	handleResponse(c, h.log, "Support teacher created successfully", http.StatusCreated, resp)
 }
 
 // GetByIdSupportTeacher godoc
@@ -47,15 +59,17 @@ func (h *handler) CreateSupportTeacher(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,full_name,group.name"
+// @Param		expand query string false "comma separated related records to embed: branch, group"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdSupportTeacher(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating support teacher id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating support teacher id", err.Error())
 		return
 	}
 
@@ -66,11 +80,21 @@ func (h *handler) GetByIdSupportTeacher(c *gin.Context) {
 	resp, err := h.grpcClient.SupportTeacher().GetById(c.Request.Context(), &supportTeacher)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting supportTeacher", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting supportTeacher")
+		return
+	}
+
+	if !h.checkBranch(c, resp.BranchId) {
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
 
-	handleResponse(c, h.log, "Support teacher got successfully", http.StatusOK, resp)
+	setETag(c, resp)
+	handleResponse(c, h.log, "Support teacher got successfully", http.StatusOK, data)
 }
 
 // UpdateSupportTeacher godoc
@@ -83,26 +107,70 @@ func (h *handler) GetByIdSupportTeacher(c *gin.Context) {
 // @Produce  	json
 // @Param		support-teacher body user_service.UpdateSupportTeacher true "support-teacher"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateSupportTeacher(c *gin.Context) {
 	supportTeacher := user_service.UpdateSupportTeacher{}
 
 	if err := c.ShouldBindJSON(&supportTeacher); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if err := pkg.Validate(&supportTeacher); err != nil {
+		handleError(c, h.log, err, "error while validating support teacher")
+		return
+	}
+
+	unlock := h.locks.lock(supportTeacher.Id)
+	defer unlock()
+
+	if !h.checkBranch(c, supportTeacher.BranchId) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.SupportTeacher().GetById(c.Request.Context(), &user_service.SupportTeacherPrimaryKey{Id: supportTeacher.Id})
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.SupportTeacher().Update(c.Request.Context(), &supportTeacher)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating a support teacher", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating a support teacher")
 		return
 	}
 	handleResponse(c, h.log, "Support teacher updated successfully", http.StatusOK, resp)
 }
 
+// PatchSupportTeacher godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/support-teacher/{id} [PATCH]
+// @Summary 	Partially update a support teacher
+// @Description API for changing only the given fields of a support teacher, fields left out keep their stored value, the password too. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		SUPPORT-TEACHERS
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the support teacher"
+// @Param		support_teacher body user_service.UpdateSupportTeacher true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	502  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchSupportTeacher(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "support teacher",
+		update: &user_service.UpdateSupportTeacher{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.SupportTeacher().GetById(ctx, &user_service.SupportTeacherPrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.SupportTeacher().Update(ctx, update.(*user_service.UpdateSupportTeacher))
+		},
+	})
+}
+
 // DeleteSupportTeacher godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/support-teacher/{id} [DELETE]
@@ -113,24 +181,31 @@ func (h *handler) UpdateSupportTeacher(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteSupportTeacher(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating support teacher id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating support teacher id", err.Error())
 		return
 	}
 
 	supportTeacher := user_service.SupportTeacherPrimaryKey{
 		Id: id,
 	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.SupportTeacher().GetById(c.Request.Context(), &supportTeacher)
+	}) {
+		return
+	}
+
 	_, err := h.grpcClient.SupportTeacher().Delete(c.Request.Context(), &supportTeacher)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting a support teacher", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting a support teacher")
 		return
 	}
 	handleResponse(c, h.log, "Support teacher deleted successfully", http.StatusOK, "Support teacher deleted successfully")
@@ -145,38 +220,54 @@ func (h *handler) DeleteSupportTeacher(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	group_id query string false "group id"
+// @Param    	is_active query boolean false "true or false"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,full_name"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,full_name,group.name"
+// @Param    	expand query string false "comma separated related records to embed: branch, group"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllSupportTeacher(c *gin.Context) {
 	search := c.Query("search")
 
-	page, err := ParsePageQueryParam(c)
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
-		return
-	}
-	limit, err := ParseLimitQueryParam(c)
-
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
 
 	supportTeachers := user_service.GetListSupportTeachersRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
 		Search: search,
 	}
 
-	resp, err := h.grpcClient.SupportTeacher().GetAll(c.Request.Context(), &supportTeachers)
+	q, err := bindListQuery(c, &supportTeachers, &user_service.SupportTeacher{})
+	if err != nil {
+		handleError(c, h.log, err, "error while parsing filters")
+		return
+	}
+
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		supportTeachers.Offset, supportTeachers.Limit = offset, limit
+		return h.grpcClient.SupportTeacher().GetAll(ctx, &supportTeachers)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all support teachers", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all support teachers")
 		return
 	}
-	handleResponse(c, h.log, "Support teachers got successfully", http.StatusOK, resp)
+
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	handleListResponse(c, h.log, "Support teachers got successfully", data, p)
 }
\ No newline at end of file
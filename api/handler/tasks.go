@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"crmapi/genproto/schedule_service/tasks"
+	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateTask godoc
@@ -18,24 +21,35 @@ import (
 // @Produce  	json
 // @Param		schedule body schedule_service.CreateTask true "schedule"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateTask(c *gin.Context) {
 	task := schedule_service.CreateTask{}
 
 	if err := c.ShouldBindJSON(&task); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a task")
+		handleError(c, h.log, err, "error while creating a task")
 		return
 	}
 
-	_, err := h.grpcClient.Tasks().Create(c.Request.Context(), &task)
+	if err := pkg.Validate(&task); err != nil {
+		handleError(c, h.log, err, "error while validating task")
+		return
+	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.lessonBranch(c.Request.Context(), task.LessonId)
+	}) {
+		return
+	}
+
+	resp, err := h.grpcClient.Tasks().Create(c.Request.Context(), &task)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a task")
+		handleError(c, h.log, err, "error while creating a task")
 		return
 	}
-	handleGrpcErrWithDescription(c, h.log, err, "Task created successfully")
+	handleResponse(c, h.log, "Task created successfully", http.StatusCreated, resp)
 }
 
 // GetByIdTask godoc
@@ -47,15 +61,16 @@ func (h *handler) CreateTask(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,label,deadline"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdTask(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating schedule id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating schedule id", err.Error())
 		return
 	}
 
@@ -66,11 +81,23 @@ func (h *handler) GetByIdTask(c *gin.Context) {
 	resp, err := h.grpcClient.Tasks().GetById(c.Request.Context(), &task)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting task", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting task")
+		return
+	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.lessonBranch(c.Request.Context(), resp.LessonId)
+	}) {
 		return
 	}
 
-	handleResponse(c, h.log, "Task got successfully", http.StatusOK, resp)
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	setETag(c, resp)
+	handleResponse(c, h.log, "Task got successfully", http.StatusOK, data)
 }
 
 // UpdateTask godoc
@@ -83,26 +110,74 @@ func (h *handler) GetByIdTask(c *gin.Context) {
 // @Produce  	json
 // @Param		schedule body schedule_service.UpdateTask true "schedule"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateTask(c *gin.Context) {
 	task := schedule_service.UpdateTask{}
 
 	if err := c.ShouldBindJSON(&task); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if err := pkg.Validate(&task); err != nil {
+		handleError(c, h.log, err, "error while validating task")
+		return
+	}
+
+	unlock := h.locks.lock(task.Id)
+	defer unlock()
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.lessonBranch(c.Request.Context(), task.LessonId)
+	}) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.storedTaskBranch(c.Request.Context(), task.Id)
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.Tasks().Update(c.Request.Context(), &task)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating a task", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating a task")
 		return
 	}
 	handleResponse(c, h.log, "Task updated successfully", http.StatusOK, resp)
 }
 
+// PatchTask godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/task/{id} [PATCH]
+// @Summary 	Partially update a task
+// @Description API for changing only the given fields of a task, fields left out keep their stored value. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		TASKS
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the task"
+// @Param		task body schedule_service.UpdateTask true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchTask(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "task",
+		update: &schedule_service.UpdateTask{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Tasks().GetById(ctx, &schedule_service.TaskPrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.Tasks().Update(ctx, update.(*schedule_service.UpdateTask))
+		},
+		branch: func(ctx context.Context, record proto.Message) (branchRecord, error) {
+			return h.lessonBranch(ctx, record.(lessonRecord).GetLessonId())
+		},
+	})
+}
+
 // DeleteTask godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/task/{id} [DELETE]
@@ -113,24 +188,30 @@ func (h *handler) UpdateTask(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteTask(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating task id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating task id", err.Error())
 		return
 	}
 
 	task := schedule_service.TaskPrimaryKey{
 		Id: id,
 	}
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.storedTaskBranch(c.Request.Context(), id)
+	}) {
+		return
+	}
+
 	_, err := h.grpcClient.Tasks().Delete(c.Request.Context(), &task)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting a task", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting a task")
 		return
 	}
 	handleResponse(c, h.log, "Task deleted successfully", http.StatusOK, "Task deleted successfully")
@@ -145,36 +226,50 @@ func (h *handler) DeleteTask(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	lesson_id query string false "lesson id"
+// @Param    	deadline_from query string false "deadline at or after"
+// @Param    	deadline_to query string false "deadline at or before, a date includes the whole day"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,id"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,label,deadline"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllTasks(c *gin.Context) {
 
-	page, err := ParsePageQueryParam(c)
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
-	limit, err := ParseLimitQueryParam(c)
 
+	tasks := schedule_service.GetListTaskRequest{}
+
+	q, err := bindListQuery(c, &tasks, &schedule_service.Task{})
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing filters")
 		return
 	}
 
-	tasks := schedule_service.GetListTaskRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
-	}
-
-	resp, err := h.grpcClient.Tasks().GetAll(c.Request.Context(), &tasks)
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		tasks.Offset, tasks.Limit = offset, limit
+		return h.grpcClient.Tasks().GetAll(ctx, &tasks)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all tasks", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all tasks")
+		return
+	}
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
-	handleResponse(c, h.log, "Tasks got successfully", http.StatusOK, resp)
+
+	handleListResponse(c, h.log, "Tasks got successfully", data, p)
 }
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"crmapi/genproto/user_service/teachers"
+	"crmapi/pkg"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
 )
 
 // CreateTeacher godoc
@@ -18,24 +21,33 @@ import (
 // @Produce  	json
 // @Param		teacher body user_service.CreateTeacher true "teacher"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) CreateTeacher(c *gin.Context) {
 	teacher := user_service.CreateTeacher{}
 
 	if err := c.ShouldBindJSON(&teacher); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a teacher")
+		handleError(c, h.log, err, "error while creating a teacher")
 		return
 	}
 
-	_, err := h.grpcClient.Teachers().Create(c.Request.Context(), &teacher)
+	if err := pkg.Validate(&teacher); err != nil {
+		handleError(c, h.log, err, "error while validating teacher")
+		return
+	}
+
+	if !h.checkBranch(c, teacher.BranchId) {
+		return
+	}
+
+	resp, err := h.grpcClient.Teachers().Create(c.Request.Context(), &teacher)
 
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while creating a teacher")
+		handleError(c, h.log, err, "error while creating a teacher")
 		return
 	}
-	handleGrpcErrWithDescription(c, h.log, err, "Teacher created successfully")
+	handleResponse(c, h.log, "Teacher created successfully", http.StatusCreated, resp)
 }
 
 // GetByIdTeacher godoc
@@ -47,15 +59,17 @@ func (h *handler) CreateTeacher(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param		id path string true "id"
+// @Param		fields query string false "comma separated fields to return, e.g. id,full_name,group.name"
+// @Param		expand query string false "comma separated related records to embed: branch, group, support_teacher"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetByIdTeacher(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating teacher id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating teacher id", err.Error())
 		return
 	}
 
@@ -66,11 +80,21 @@ func (h *handler) GetByIdTeacher(c *gin.Context) {
 	resp, err := h.grpcClient.Teachers().GetById(c.Request.Context(), &teacher)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting teacher", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting teacher")
+		return
+	}
+
+	if !h.checkBranch(c, resp.BranchId) {
+		return
+	}
+
+	data, ok := h.shape(c, resp)
+	if !ok {
 		return
 	}
 
-	handleResponse(c, h.log, "Teacher got successfully", http.StatusOK, resp)
+	setETag(c, resp)
+	handleResponse(c, h.log, "Teacher got successfully", http.StatusOK, data)
 }
 
 // UpdateTeacher godoc
@@ -83,26 +107,70 @@ func (h *handler) GetByIdTeacher(c *gin.Context) {
 // @Produce  	json
 // @Param		teacher body user_service.UpdateTeacher true "teacher"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) UpdateTeacher(c *gin.Context) {
 	teacher := user_service.UpdateTeacher{}
 
 	if err := c.ShouldBindJSON(&teacher); err != nil {
-		handleResponse(c, h.log, "error while reading request body", http.StatusBadRequest, err)
+		handleError(c, h.log, err, "error while reading request body")
+		return
+	}
+
+	if err := pkg.Validate(&teacher); err != nil {
+		handleError(c, h.log, err, "error while validating teacher")
+		return
+	}
+
+	unlock := h.locks.lock(teacher.Id)
+	defer unlock()
+
+	if !h.checkBranch(c, teacher.BranchId) || !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Teachers().GetById(c.Request.Context(), &user_service.TeacherPrimaryKey{Id: teacher.Id})
+	}) {
 		return
 	}
 
 	resp, err := h.grpcClient.Teachers().Update(c.Request.Context(), &teacher)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while updating a teacher", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while updating a teacher")
 		return
 	}
 	handleResponse(c, h.log, "Teacher updated successfully", http.StatusOK, resp)
 }
 
+// PatchTeacher godoc
+// @Security ApiKeyAuth
+// @Router 		/api/v1/teacher/{id} [PATCH]
+// @Summary 	Partially update a teacher
+// @Description API for changing only the given fields of a teacher, fields left out keep their stored value, the password too. Send the ETag of GET in If-Match to update only that version, concurrent writes are serialized per gateway instance only
+// @Tags 		TEACHERS
+// @Accept  	json
+// @Produce  	json
+// @Param		id path string true "id"
+// @Param		If-Match header string false "ETag of the teacher"
+// @Param		teacher body user_service.UpdateTeacher true "fields to change"
+// @Success		200  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	412  {object} models.Problem
+// @Failure 	502  {object} models.Problem
+// @Failure 	500  {object} models.Problem
+func (h *handler) PatchTeacher(c *gin.Context) {
+	h.patch(c, patchTarget{
+		name:   "teacher",
+		update: &user_service.UpdateTeacher{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.Teachers().GetById(ctx, &user_service.TeacherPrimaryKey{Id: id})
+		},
+		save: func(ctx context.Context, update proto.Message) (proto.Message, error) {
+			return h.grpcClient.Teachers().Update(ctx, update.(*user_service.UpdateTeacher))
+		},
+	})
+}
+
 // DeleteTeacher godoc
 // @Security ApiKeyAuth
 // @Router 		/api/v1/teacher/{id} [DELETE]
@@ -113,24 +181,31 @@ func (h *handler) UpdateTeacher(c *gin.Context) {
 // @Produce  	json
 // @Param		id path string true "id"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) DeleteTeacher(c *gin.Context) {
 	id := c.Param("id")
 
 	if err := uuid.Validate(id); err != nil {
-		handleResponse(c, h.log, "error while validating teacher id", http.StatusBadRequest, err.Error())
+		handleProblem(c, h.log, http.StatusBadRequest, ErrorCodeInvalidURL, "error while validating teacher id", err.Error())
 		return
 	}
 
 	teacher := user_service.TeacherPrimaryKey{
 		Id: id,
 	}
+
+	if !h.checkRecordBranch(c, func() (branchRecord, error) {
+		return h.grpcClient.Teachers().GetById(c.Request.Context(), &teacher)
+	}) {
+		return
+	}
+
 	_, err := h.grpcClient.Teachers().Delete(c.Request.Context(), &teacher)
 
 	if err != nil {
-		handleResponse(c, h.log, "error while deleting a teacher", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while deleting a teacher")
 		return
 	}
 	handleResponse(c, h.log, "Teacher deleted successfully", http.StatusOK, "Teacher deleted successfully")
@@ -145,38 +220,55 @@ func (h *handler) DeleteTeacher(c *gin.Context) {
 // @Accept  	json
 // @Produce  	json
 // @Param   	search query string false "search"
-// @Param    	page query int false "page"
-// @Param    	limit query int false "limit"
+// @Param    	page query int false "page, ignored with a cursor"
+// @Param    	limit query int false "page size, up to MAX_PAGE_SIZE"
+// @Param    	cursor query string false "opaque cursor from Pagination.next_cursor or prev_cursor, issued for filtered, sorted or branch scoped lists, e.g. sort=created_at"
+// @Param    	branch_id query string false "branch id"
+// @Param    	group_id query string false "group id"
+// @Param    	support_teacher_id query string false "support teacher id"
+// @Param    	is_active query boolean false "true or false"
+// @Param    	created_from query string false "created at or after, 2006-01-02 or 2006-01-02 15:04:05"
+// @Param    	created_to query string false "created at or before, a date includes the whole day"
+// @Param    	sort query string false "comma separated fields to sort by, - for descending, e.g. -created_at,full_name"
+// @Param    	fields query string false "comma separated fields to return, e.g. id,full_name,group.name"
+// @Param    	expand query string false "comma separated related records to embed: branch, group, support_teacher"
 // @Success		200  {object} models.Response
-// @Failure		400  {object} models.Response
-// @Failure 	404  {object} models.Response
-// @Failure 	500  {object} models.Response
+// @Failure		400  {object} models.Problem
+// @Failure 	404  {object} models.Problem
+// @Failure 	500  {object} models.Problem
 func (h *handler) GetAllTeachers(c *gin.Context) {
 	search := c.Query("search")
 
-	page, err := ParsePageQueryParam(c)
+	p, err := h.parsePagination(c)
 	if err != nil {
-		handleResponse(c, h.log, "error while parsing page", http.StatusBadRequest, err.Error())
-		return
-	}
-	limit, err := ParseLimitQueryParam(c)
-
-	if err != nil {
-		handleResponse(c, h.log, "error while parsing limit", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while parsing pagination")
 		return
 	}
 
 	teachers := user_service.GetListTeachersRequest{
-		Offset: (page - 1) * limit,
-		Limit:  limit,
 		Search: search,
 	}
 
-	resp, err := h.grpcClient.Teachers().GetAll(c.Request.Context(), &teachers)
+	q, err := bindListQuery(c, &teachers, &user_service.Teacher{})
+	if err != nil {
+		handleError(c, h.log, err, "error while parsing filters")
+		return
+	}
+
+	resp, p, err := h.listRecords(c.Request.Context(), p, q, func(ctx context.Context, offset, limit int64) (proto.Message, error) {
+		teachers.Offset, teachers.Limit = offset, limit
+		return h.grpcClient.Teachers().GetAll(ctx, &teachers)
+	})
 
 	if err != nil {
-		handleResponse(c, h.log, "error while getting all teachers", http.StatusBadRequest, err.Error())
+		handleError(c, h.log, err, "error while getting all teachers")
 		return
 	}
-	handleResponse(c, h.log, "Teachers got successfully", http.StatusOK, resp)
+
+	data, ok := h.shape(c, resp)
+	if !ok {
+		return
+	}
+
+	handleListResponse(c, h.log, "Teachers got successfully", data, p)
 }
\ No newline at end of file
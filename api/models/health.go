@@ -0,0 +1,16 @@
+package models
+
+// HealthResponse is returned by the liveness and readiness probes
+type HealthResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+// HealthCheck is the status of one dependency of the gateway
+type HealthCheck struct {
+	Status    string  `json:"status"`
+	State     string  `json:"state,omitempty"`
+	Circuit   string  `json:"circuit,omitempty"`
+	LatencyMs float64 `json:"latency_ms,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
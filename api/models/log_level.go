@@ -0,0 +1,8 @@
+package models
+
+// LogLevel is the global level of the gateway logger and its per-namespace overrides
+type LogLevel struct {
+	Level string `json:"level,omitempty"`
+	// Namespaces maps a logger name to its level, an empty level removes the override
+	Namespaces map[string]string `json:"namespaces,omitempty"`
+}
@@ -32,6 +32,19 @@ type Response struct {
 	StatusCode  int
 	Description string
 	Data        interface{}
+	Pagination  *Pagination `json:",omitempty"`
+}
+
+// Pagination describes the page of a list response. The cursors are opaque,
+// pass them back as ?cursor= to get the next or the previous page.
+type Pagination struct {
+	Total      int64  `json:"total"`
+	Page       int64  `json:"page"`
+	Limit      int64  `json:"limit"`
+	Offset     int64  `json:"offset"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 type ErrorWithDescription struct {
@@ -45,4 +58,15 @@ type ErrorReason struct {
 
 type ResponseResult struct {
 	Result string `json:"result"`
-}
\ No newline at end of file
+}
+
+// Problem is an RFC 7807 error body, Type is one of the handler ErrorCode* constants
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
+}
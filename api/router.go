@@ -2,15 +2,22 @@ package api
 
 import (
 	"net/http"
+	"strings"
+	"sync/atomic"
 
 	_ "crmapi/api/docs" //for swagger
 	"crmapi/api/handler"
 	"crmapi/config"
 	"crmapi/pkg/grpc_client"
 	"crmapi/pkg/logger"
+	"crmapi/pkg/metrics"
+	"crmapi/pkg/rbac"
+	"crmapi/pkg/requestid"
+	"crmapi/pkg/tracing"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
@@ -20,6 +27,8 @@ type Config struct {
 	Logger     logger.Logger
 	GrpcClient *grpc_client.GrpcClient
 	Cfg        config.Config
+	Policy     *rbac.Policy
+	Ready      *atomic.Bool
 }
 
 // @title           CRM API
@@ -44,121 +53,169 @@ type Config struct {
 func New(cnf Config) *gin.Engine {
 	r := gin.New()
 
-	r.Use(gin.Logger())
+	handler := handler.New(&handler.HandlerConfig{
+		Logger:     cnf.Logger,
+		GrpcClient: cnf.GrpcClient,
+		Cfg:        cnf.Cfg,
+		Policy:     cnf.Policy,
+		Ready:      cnf.Ready,
+	})
+
+	r.Use(otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/healthz" && r.URL.Path != "/readyz" && r.URL.Path != "/metrics" && !strings.HasPrefix(r.URL.Path, "/swagger/")
+	})))
+
+	r.Use(handler.RequestID())
+
+	r.Use(handler.Metrics())
+
+	r.Use(handler.AccessLog())
 
 	r.Use(gin.Recovery())
 
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
 	config.AllowHeaders = append(config.AllowHeaders, "*")
+	config.ExposeHeaders = append(config.ExposeHeaders, requestid.Header, "ETag", "Link")
 
 	r.Use(cors.New(config))
 
-	handler := handler.New(&handler.HandlerConfig{
-		Logger:     cnf.Logger,
-		GrpcClient: cnf.GrpcClient,
-		Cfg:        cnf.Cfg,
-	})
-
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"data": "Api gateway"})
 	})
 
+	// probes
+	r.GET("/healthz", handler.Healthz)
+	r.GET("/readyz", handler.Readyz)
+
+	// prometheus
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	// auth
+	r.POST("/api/v1/auth/login", handler.Login)
+	r.POST("/api/v1/auth/refresh", handler.RefreshToken)
+
+	v1 := r.Group("/api/v1", handler.AuthMiddleware(), handler.RBACMiddleware())
+
+	// me
+	v1.POST("/me/password", handler.ChangePassword)
+
+	// system
+	v1.GET("/system/log-level", handler.GetLogLevel)
+	v1.PUT("/system/log-level", handler.SetLogLevel)
+
 	// super-admins
-	r.POST("/api/v1/super-admin", handler.CreateSuperAdmin)
-	r.GET("/api/v1/super-admin/:id", handler.GetByIdSuperAdmin)
-	r.PUT("/api/v1/super-admin", handler.UpdateSuperAdmin)
-	r.DELETE("/api/v1/super-admin/:id", handler.DeleteSuperAdmin)
-	r.GET("/api/v1/super-admins", handler.GetAllSuperAdmin)
+	v1.POST("/super-admin", handler.CreateSuperAdmin)
+	v1.GET("/super-admin/:id", handler.GetByIdSuperAdmin)
+	v1.PUT("/super-admin", handler.UpdateSuperAdmin)
+	v1.PATCH("/super-admin/:id", handler.PatchSuperAdmin)
+	v1.DELETE("/super-admin/:id", handler.DeleteSuperAdmin)
+	v1.GET("/super-admins", handler.GetAllSuperAdmin)
 
 	// branches
-	r.POST("/api/v1/branch", handler.CreateBranch)
-	r.GET("/api/v1/branch/:id", handler.GetByIdBranch)
-	r.PUT("/api/v1/branch", handler.UpdateBranch)
-	r.DELETE("/api/v1/branch/:id", handler.DeleteBranch)
-	r.GET("/api/v1/branches", handler.GetAllBranches)
+	v1.POST("/branch", handler.CreateBranch)
+	v1.GET("/branch/:id", handler.GetByIdBranch)
+	v1.PUT("/branch", handler.UpdateBranch)
+	v1.PATCH("/branch/:id", handler.PatchBranch)
+	v1.DELETE("/branch/:id", handler.DeleteBranch)
+	v1.GET("/branches", handler.GetAllBranches)
 
 	// admins
-	r.POST("/api/v1/admin", handler.CreateAdmin)
-	r.GET("/api/v1/admin/:id", handler.GetByIdAdmin)
-	r.PUT("/api/v1/admin", handler.UpdateAdmin)
-	r.DELETE("/api/v1/admin/:id", handler.DeleteAdmin)
-	r.GET("/api/v1/admins", handler.GetAllAdmins)
+	v1.POST("/admin", handler.CreateAdmin)
+	v1.GET("/admin/:id", handler.GetByIdAdmin)
+	v1.PUT("/admin", handler.UpdateAdmin)
+	v1.PATCH("/admin/:id", handler.PatchAdmin)
+	v1.DELETE("/admin/:id", handler.DeleteAdmin)
+	v1.GET("/admins", handler.GetAllAdmins)
 
 	// events
-	r.POST("/api/v1/event", handler.CreateEvent)
-	r.GET("/api/v1/event/:id", handler.GetByIdEvent)
-	r.PUT("/api/v1/event", handler.UpdateEvent)
-	r.DELETE("/api/v1/event/:id", handler.DeleteEvent)
-	r.GET("/api/v1/events", handler.GetAllEvents)
+	v1.POST("/event", handler.CreateEvent)
+	v1.GET("/event/:id", handler.GetByIdEvent)
+	v1.PUT("/event", handler.UpdateEvent)
+	v1.PATCH("/event/:id", handler.PatchEvent)
+	v1.DELETE("/event/:id", handler.DeleteEvent)
+	v1.GET("/events", handler.GetAllEvents)
 
 	// groups
-	r.POST("/api/v1/group", handler.CreateGroup)
-	r.GET("/api/v1/group/:id", handler.GetByIdGroup)
-	r.PUT("/api/v1/group", handler.UpdateGroup)
-	r.DELETE("/api/v1/group/:id", handler.DeleteGroup)
-	r.GET("/api/v1/groups", handler.GetAllGroups)
+	v1.POST("/group", handler.CreateGroup)
+	v1.GET("/group/:id", handler.GetByIdGroup)
+	v1.PUT("/group", handler.UpdateGroup)
+	v1.PATCH("/group/:id", handler.PatchGroup)
+	v1.DELETE("/group/:id", handler.DeleteGroup)
+	v1.GET("/groups", handler.GetAllGroups)
 
 	// join-event
-	r.POST("/api/v1/join-event", handler.CreateJoinEvent)
-	r.GET("/api/v1/join-event/:id", handler.GetByIdEventJoin)
-	r.DELETE("/api/v1/join-event/:id", handler.DeleteEventJoin)
-// full
+	v1.POST("/join-event", handler.CreateJoinEvent)
+	v1.GET("/join-event/:id", handler.GetByIdEventJoin)
+	v1.DELETE("/join-event/:id", handler.DeleteEventJoin)
 	// managers
-	r.POST("/api/v1/manager", handler.CreateManager)
-	r.GET("/api/v1/manager/:id", handler.GetByIdManager)
-	r.PUT("/api/v1/manager", handler.UpdateManager)
-	r.DELETE("/api/v1/manager/:id", handler.DeleteManager)
-	r.GET("/api/v1/managers", handler.GetAllManagers)
+	v1.POST("/manager", handler.CreateManager)
+	v1.GET("/manager/:id", handler.GetByIdManager)
+	v1.PUT("/manager", handler.UpdateManager)
+	v1.PATCH("/manager/:id", handler.PatchManager)
+	v1.DELETE("/manager/:id", handler.DeleteManager)
+	v1.GET("/managers", handler.GetAllManagers)
 
 	// students
-	r.POST("/api/v1/student", handler.CreateStudent)
-	r.GET("/api/v1/student/:id", handler.GetByIdStudent)
-	r.PUT("/api/v1/student", handler.UpdateStudent)
-	r.DELETE("/api/v1/student/:id", handler.DeleteStudent)
-	r.GET("/api/v1/students", handler.GetAllStudents)
+	v1.POST("/student", handler.CreateStudent)
+	v1.GET("/student/:id", handler.GetByIdStudent)
+	v1.PUT("/student", handler.UpdateStudent)
+	v1.PATCH("/student/:id", handler.PatchStudent)
+	v1.DELETE("/student/:id", handler.DeleteStudent)
+	v1.GET("/students", handler.GetAllStudents)
 
 	// support-teachers
-	r.POST("/api/v1/support-teacher", handler.CreateSupportTeacher)
-	r.GET("/api/v1/support-teacher/:id", handler.GetByIdSupportTeacher)
-	r.PUT("/api/v1/support-teacher", handler.UpdateSupportTeacher)
-	r.DELETE("/api/v1/support-teacher/:id", handler.DeleteSupportTeacher)
-	r.GET("/api/v1/support-teachers", handler.GetAllSupportTeacher)
+	v1.POST("/support-teacher", handler.CreateSupportTeacher)
+	v1.GET("/support-teacher/:id", handler.GetByIdSupportTeacher)
+	v1.PUT("/support-teacher", handler.UpdateSupportTeacher)
+	v1.PATCH("/support-teacher/:id", handler.PatchSupportTeacher)
+	v1.DELETE("/support-teacher/:id", handler.DeleteSupportTeacher)
+	v1.GET("/support-teachers", handler.GetAllSupportTeacher)
 
 	// teachers
-	r.POST("/api/v1/teacher", handler.CreateTeacher)
-	r.GET("/api/v1/teacher/:id", handler.GetByIdTeacher)
-	r.PUT("/api/v1/teacher", handler.UpdateTeacher)
-	r.DELETE("/api/v1/teacher/:id", handler.DeleteTeacher)
-	r.GET("/api/v1/teachers", handler.GetAllTeachers)
+	v1.POST("/teacher", handler.CreateTeacher)
+	v1.GET("/teacher/:id", handler.GetByIdTeacher)
+	v1.PUT("/teacher", handler.UpdateTeacher)
+	v1.PATCH("/teacher/:id", handler.PatchTeacher)
+	v1.DELETE("/teacher/:id", handler.DeleteTeacher)
+	v1.GET("/teachers", handler.GetAllTeachers)
 
 	// schedule
-	r.POST("/api/v1/schedule", handler.CreateSchedule)
-	r.GET("/api/v1/schedule/:id", handler.GetByIdSchedule)
-	r.PUT("/api/v1/schedule", handler.UpdateSchedule)
-	r.DELETE("/api/v1/schedule/:id", handler.DeleteSchedule)
-	r.GET("/api/v1/schedules", handler.GetAllSchedules)
+	v1.POST("/schedule", handler.CreateSchedule)
+	v1.GET("/schedule/:id", handler.GetByIdSchedule)
+	v1.PUT("/schedule", handler.UpdateSchedule)
+	v1.PATCH("/schedule/:id", handler.PatchSchedule)
+	v1.DELETE("/schedule/:id", handler.DeleteSchedule)
+	v1.GET("/schedules", handler.GetAllSchedules)
 
 	// tasks
-	r.POST("/api/v1/task", handler.CreateTask)
-	r.GET("/api/v1/task/:id", handler.GetByIdTask)
-	r.PUT("/api/v1/task", handler.UpdateTask)
-	r.DELETE("/api/v1/task/:id", handler.DeleteTask)
-	r.GET("/api/v1/tasks", handler.GetAllTasks)
+	v1.POST("/task", handler.CreateTask)
+	v1.GET("/task/:id", handler.GetByIdTask)
+	v1.PUT("/task", handler.UpdateTask)
+	v1.PATCH("/task/:id", handler.PatchTask)
+	v1.DELETE("/task/:id", handler.DeleteTask)
+	v1.GET("/tasks", handler.GetAllTasks)
 
 	// lessons
-	r.POST("/api/v1/lesson", handler.CreateLesson)
-	r.GET("/api/v1/lesson/:id", handler.GetByIdLesson)
-	r.PUT("/api/v1/lesson", handler.UpdateLesson)
-	r.GET("/api/v1/lessons", handler.GetAllLessons)
+	v1.POST("/lesson", handler.CreateLesson)
+	v1.GET("/lesson/:id", handler.GetByIdLesson)
+	v1.PUT("/lesson", handler.UpdateLesson)
+	v1.PATCH("/lesson/:id", handler.PatchLesson)
+	v1.GET("/lessons", handler.GetAllLessons)
 
 	// attendances
-	r.POST("/api/v1/attendance", handler.CreateAttendance)
-	r.GET("/api/v1/attendance/:id", handler.GetByIdAttendance)
-	r.PUT("/api/v1/attendance", handler.UpdateAttendance)
-	r.DELETE("/api/v1/attendance/:id", handler.DeleteAttendance)
-	r.GET("/api/v1/attendances", handler.GetAllAttendances)
-
+	v1.POST("/attendance", handler.CreateAttendance)
+	v1.GET("/attendance/:id", handler.GetByIdAttendance)
+	v1.PUT("/attendance", handler.UpdateAttendance)
+	v1.PATCH("/attendance/:id", handler.PatchAttendance)
+	v1.DELETE("/attendance/:id", handler.DeleteAttendance)
+	v1.GET("/attendances", handler.GetAllAttendances)
+
+	for _, route := range r.Routes() {
+		if strings.HasPrefix(route.Path, "/api/v1/") && !strings.HasPrefix(route.Path, "/api/v1/auth/") && !cnf.Policy.Has(route.Method, route.Path) {
+			cnf.Logger.Warn("route is missing from rbac policy and will be denied", logger.String("method", route.Method), logger.String("path", route.Path))
+		}
+	}
 
 	// Shipper endpoints
 	url := ginSwagger.URL("swagger/doc.json") // The url pointing to API definition
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"crmapi/config"
+	st "crmapi/genproto/user_service/students"
+	"crmapi/pkg/grpc_client"
+	"crmapi/pkg/jwt"
+	"crmapi/pkg/logger"
+	"crmapi/pkg/rbac"
+	"crmapi/pkg/tracing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	testStudentID = "9f1c2b4e-6a7d-4c1e-8f3a-2b5d7e9c1a3f"
+	testSecret    = "test-secret"
+)
+
+type studentServer struct {
+	st.UnimplementedStudentServiceServer
+}
+
+func (studentServer) GetById(_ context.Context, req *st.StudentPrimaryKey) (*st.Student, error) {
+	return &st.Student{Id: req.Id, FullName: "Ali Valiyev", BranchId: "b1"}, nil
+}
+
+// startBackend serves the student service and the health protocol on a local port, with
+// server spans so that the trace context sent by the gateway can be checked
+func startBackend(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	st.RegisterStudentServiceServer(srv, studentServer{})
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	return port
+}
+
+// TestRequestSpans sends a request with a traceparent header through the router and the
+// grpc client of the gateway: the request continues the incoming trace with a server span,
+// the backend call is its child client span and the backend continues the same trace
+func TestRequestSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(tracing.Config{ServiceName: tracing.ServiceName, SampleRatio: 1}, sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+
+	port := startBackend(t)
+	cfg := config.Config{
+		UserServiceHost:        "127.0.0.1",
+		UserServicePort:        port,
+		ScheduleServiceHost:    "127.0.0.1",
+		ScheduleServicePort:    port,
+		UserServiceTimeout:     5 * time.Second,
+		ScheduleServiceTimeout: 5 * time.Second,
+		SecretKey:              testSecret,
+		SensitiveFields:        []string{"password"},
+		DefaultLanguage:        "en",
+		DefaultPageSize:        10,
+		MaxPageSize:            100,
+	}
+	log := logger.New("error", "test")
+
+	grpcClient, err := grpc_client.New(cfg, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer grpcClient.Close()
+
+	policy, err := rbac.Load("../config/rbac.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := New(Config{Logger: log, GrpcClient: grpcClient, Cfg: cfg, Policy: policy})
+
+	token, err := jwt.GenerateJWT(jwt.Claims{UserID: "u1", UserRole: "super_admin", TokenType: jwt.AccessToken}, time.Minute, testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/student/"+testStudentID, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+
+	// client side health checking adds spans of its own, the call is picked by its name
+	method := strings.TrimPrefix(st.StudentService_GetById_FullMethodName, "/")
+	var server, client, backend *tracetest.SpanStub
+	spans := exporter.GetSpans()
+	for i := range spans {
+		switch {
+		case spans[i].SpanKind == trace.SpanKindServer && spans[i].Name == "/api/v1/student/:id":
+			server = &spans[i]
+		case spans[i].SpanKind == trace.SpanKindClient && spans[i].Name == method:
+			client = &spans[i]
+		case spans[i].SpanKind == trace.SpanKindServer && spans[i].Name == method:
+			backend = &spans[i]
+		}
+	}
+	if server == nil || client == nil || backend == nil {
+		t.Fatalf("want a gateway span of the route, a client and a backend span of %s, got %d spans: %+v", method, len(spans), spans)
+	}
+
+	if got := server.SpanContext.TraceID().String(); got != traceID {
+		t.Errorf("server span trace id = %s, want the incoming %s", got, traceID)
+	}
+	if client.Parent.SpanID() != server.SpanContext.SpanID() || client.SpanContext.TraceID() != server.SpanContext.TraceID() {
+		t.Errorf("client span parent = %s, want the server span %s", client.Parent.SpanID(), server.SpanContext.SpanID())
+	}
+	if backend.Parent.SpanID() != client.SpanContext.SpanID() || backend.SpanContext.TraceID() != server.SpanContext.TraceID() {
+		t.Errorf("backend span parent = %s, want the client span %s", backend.Parent.SpanID(), client.SpanContext.SpanID())
+	}
+}
@@ -1,37 +1,195 @@
 package main
 
 import (
+	"context"
 	"crmapi/api"
 	"crmapi/config"
+	"crmapi/pkg/certs"
 	"crmapi/pkg/grpc_client"
 	"crmapi/pkg/logger"
+	"crmapi/pkg/rbac"
+	"crmapi/pkg/tracing"
+	"errors"
+	"flag"
+	"fmt"
+	stdlog "log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
 )
 
 var (
 	log        logger.Logger
 	cfg        config.Config
 	grpcClient *grpc_client.GrpcClient
+	policy     *rbac.Policy
 )
 
 func initDeps() {
 	var err error
-	cfg = config.Load()
-	log = logger.New(cfg.LogLevel, "crm-api-gateway")
+	flags, err := config.ParseFlags(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		os.Exit(2)
+	}
+	cfg = config.Load(flags)
+
+	if flags.PrintConfig {
+		_ = cfg.Print(os.Stdout)
+		if err = cfg.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	log, err = logger.NewWithConfig(logger.Config{
+		Level:           cfg.LogLevel,
+		Namespace:       "crm-api-gateway",
+		NamespaceLevels: cfg.LogNamespaceLevels,
+		File:            cfg.LogFile,
+		FileMaxSizeMB:   cfg.LogFileMaxSizeMB,
+		FileMaxAgeDays:  cfg.LogFileMaxAgeDays,
+		FileMaxBackups:  cfg.LogFileMaxBackups,
+		FileCompress:    cfg.LogFileCompress,
+	})
+	if err != nil {
+		stdlog.Fatalf("error while creating logger: %v", err)
+	}
+
+	if err = cfg.Validate(); err != nil {
+		log.Fatal("invalid configuration", logger.Error(err))
+	}
 
-	grpcClient, err = grpc_client.New(cfg)
+	policy, err = rbac.Load(cfg.RBACPolicyPath)
 	if err != nil {
-		log.Error("grpc dial error", logger.Error(err))
+		log.Fatal("error while loading rbac policy", logger.String("path", cfg.RBACPolicyPath), logger.Error(err))
+	}
+
+	grpcClient, err = grpc_client.New(cfg, log)
+	if err != nil {
+		log.Fatal("grpc dial error", logger.Error(err))
+	}
+
+	checkBackends()
+}
+
+// checkBackends waits for the backends at startup. In fail mode a backend that is not serving
+// stops the gateway, in degraded mode the gateway starts and its routes answer 503 until it recovers.
+func checkBackends() {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.BackendStartupTimeout)
+	defer cancel()
+
+	for _, s := range grpcClient.CheckHealth(ctx, true) {
+		if s.Serving {
+			log.Info("backend is serving", logger.String("backend", s.Name), logger.String("target", s.Target))
+			continue
+		}
+
+		fields := []logger.Field{logger.String("backend", s.Name), logger.String("target", s.Target), logger.String("state", s.State), logger.String("status", s.Status), logger.String("error", s.Error)}
+		if cfg.BackendStartupMode == "fail" {
+			log.Fatal("backend is not serving", fields...)
+		}
+		log.Warn("backend is not serving, starting in degraded mode", fields...)
 	}
 }
 
 func main() {
 	initDeps()
+	defer logger.Cleanup(log)
 
-	server := api.New(api.Config{
-		Logger:     log,
-		GrpcClient: grpcClient,
-		Cfg:        cfg,
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: tracing.ServiceName,
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		Insecure:    cfg.TracingInsecure,
+		SampleRatio: cfg.TracingSampleRatio,
+		Environment: cfg.Environment,
 	})
+	if err != nil {
+		log.Fatal("error while setting up tracing", logger.Error(err))
+	}
+
+	ready := &atomic.Bool{}
+	ready.Store(true)
+
+	server := &http.Server{
+		Addr: config.Address("", cfg.HTTPPort),
+		Handler: api.New(api.Config{
+			Logger:     log,
+			GrpcClient: grpcClient,
+			Cfg:        cfg,
+			Policy:     policy,
+			Ready:      ready,
+		}),
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		if !cfg.HTTPTLS.Enabled {
+			log.Info("http server is listening", logger.String("addr", server.Addr))
+			serverErr <- server.ListenAndServe()
+			return
+		}
+
+		reloader, err := certs.NewReloader(cfg.HTTPTLS.CertFile, cfg.HTTPTLS.KeyFile, cfg.HTTPTLS.CAFile, cfg.TLSReloadInterval, func(err error) {
+			log.Error("error while reloading http certificates", logger.Error(err))
+		})
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer reloader.Close()
+
+		server.TLSConfig = reloader.ServerConfig()
+		log.Info("https server is listening", logger.String("addr", server.Addr), logger.Bool("mutual_tls", cfg.HTTPTLS.CAFile != ""))
+		serverErr <- server.ListenAndServeTLS("", "")
+	}()
+
+	select {
+	case err := <-serverErr:
+		log.Error("http server stopped", logger.Error(err))
+	case <-ctx.Done():
+		stop()
+		shutdown(server, ready)
+	}
+
+	if err := grpcClient.Close(); err != nil {
+		log.Error("error while closing grpc connections", logger.Error(err))
+	}
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := shutdownTracing(flushCtx); err != nil {
+		log.Error("error while flushing spans", logger.Error(err))
+	}
+	log.Info("gateway stopped")
+}
+
+// shutdown fails readiness first so that load balancers stop sending traffic,
+// then waits for in-flight requests up to the shutdown timeout
+func shutdown(server *http.Server, ready *atomic.Bool) {
+	log.Info("shutting down", logger.String("delay", cfg.ShutdownDelay.String()), logger.String("timeout", cfg.ShutdownTimeout.String()))
+	ready.Store(false)
+	time.Sleep(cfg.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
 
-	server.Run(cfg.HTTPPort)
-}
\ No newline at end of file
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error("in-flight requests were not drained in time", logger.Error(err))
+		server.Close()
+	}
+}
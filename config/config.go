@@ -2,46 +2,203 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
-	"github.com/spf13/cast"
 )
 
 // Config ...
 type Config struct {
-	Environment   string // develop, staging, production
-	UserServiceHost string
-	UserServicePort string
+	Environment         string // develop, staging, production
+	UserServiceHost     string
+	UserServicePort     string
 	ScheduleServiceHost string
 	ScheduleServicePort string
-	LogLevel string
-	HTTPPort string
+	LogLevel            string
+	HTTPPort            string
+
+	SecretKey       string // SECRET_KEY or the file named by SECRET_KEY_FILE
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	PasswordChangeMaxAttempts int // wrong old passwords a user may send per window
+	PasswordChangeWindow      time.Duration
+
+	RBACPolicyPath string
+
+	SensitiveFields []string
+
+	DefaultLanguage string // uz, ru, en
+
+	DefaultPageSize int
+	MaxPageSize     int // largest limit a list request may ask for
+
+	BackendStartupMode    string // fail, degraded
+	BackendStartupTimeout time.Duration
+	ReadinessTimeout      time.Duration
+
+	UserServiceTimeout     time.Duration
+	ScheduleServiceTimeout time.Duration
+	RPCTimeouts            map[string]time.Duration // per method, e.g. StudentService/GetAll=3s
+	RPCMaxRetries          int
+	RPCRetryBaseDelay      time.Duration
+	RPCRetryMaxDelay       time.Duration
+
+	BreakerFailureThreshold int // 0 disables the circuit breakers
+	BreakerOpenTimeout      time.Duration
+
+	HTTPReadTimeout       time.Duration
+	HTTPReadHeaderTimeout time.Duration
+	HTTPWriteTimeout      time.Duration
+	HTTPIdleTimeout       time.Duration
+	ShutdownDelay         time.Duration // time between failing readiness and draining
+	ShutdownTimeout       time.Duration
+
+	UserServiceTLS     TLS
+	ScheduleServiceTLS TLS
+	HTTPTLS            TLS // serves the gateway over https when the certificate is set
+	TLSReloadInterval  time.Duration
+
+	TracingExporter    string // none, otlp, stdout
+	TracingEndpoint    string
+	TracingInsecure    bool
+	TracingSampleRatio float64
+
+	AccessLogSampleRate   float64 // share of successful requests logged, failures are always logged
+	AccessLogExcludePaths []string
+	AccessLogRedactQuery  []string
+
+	LogNamespaceLevels string // namespace=level pairs, e.g. crm-api-gateway=debug
+	LogFile            string // empty disables the file sink
+	LogFileMaxSizeMB   int
+	LogFileMaxAgeDays  int
+	LogFileMaxBackups  int
+	LogFileCompress    bool
+
+	settings []Setting // effective values for -print-config
+	problems []error   // values that could not be read, reported by Validate
 }
 
-// Load loads environment vars and inflates Config
-func Load() Config {
+// TLS describes the certificates of one connection. A CA file on the http side
+// requires clients to present a certificate (mutual TLS).
+type TLS struct {
+	Enabled    bool
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
+}
 
+// Load inflates Config from, in order of precedence, the -set flags, environment
+// vars (and .env), the config file and the defaults. Problems found while reading
+// are kept and reported by Validate together with the invalid values.
+func Load(f Flags) Config {
 	if err := godotenv.Load(); err != nil {
-		fmt.Println("No .env file found")
+		fmt.Fprintln(os.Stderr, "No .env file found")
+	}
+
+	var file map[string]string
+	var fileErr error
+	if f.File != "" {
+		file, fileErr = readFile(f.File)
+	}
+
+	s := newSource(file, f.Values)
+	if fileErr != nil {
+		s.errs = append(s.errs, fileErr)
 	}
 	c := Config{}
 
-	c.Environment = cast.ToString(getOrReturnDefault("ENVIRONMENT", ""))
+	c.Environment = s.string("ENVIRONMENT", "develop")
+
+	c.LogLevel = s.string("LOG_LEVEL", "info")
+	c.HTTPPort = s.string("HTTP_PORT", "8080")
+
+	c.UserServiceHost = s.string("USER_SERVICE_HOST", "")
+	c.UserServicePort = s.string("USER_GRPC_PORT", "")
+	c.ScheduleServiceHost = s.string("SCHEDULE_SERVICE_HOST", "")
+	c.ScheduleServicePort = s.string("SCHEDULE_GRPC_PORT", "")
+
+	c.SecretKey = s.secret("SECRET_KEY")
+	c.AccessTokenTTL = s.duration("ACCESS_TOKEN_TTL", "1h")
+	c.RefreshTokenTTL = s.duration("REFRESH_TOKEN_TTL", "168h")
+
+	c.PasswordChangeMaxAttempts = s.int("PASSWORD_CHANGE_MAX_ATTEMPTS", 5)
+	c.PasswordChangeWindow = s.duration("PASSWORD_CHANGE_WINDOW", "15m")
+
+	c.RBACPolicyPath = s.string("RBAC_POLICY_PATH", "./config/rbac.yaml")
+
+	c.SensitiveFields = s.list("SENSITIVE_FIELDS", "password")
+
+	c.DefaultLanguage = s.string("DEFAULT_LANGUAGE", "en")
+
+	c.DefaultPageSize = s.int("DEFAULT_PAGE_SIZE", 10)
+	c.MaxPageSize = s.int("MAX_PAGE_SIZE", 100)
+
+	c.BackendStartupMode = s.string("BACKEND_STARTUP_MODE", "degraded")
+	c.BackendStartupTimeout = s.duration("BACKEND_STARTUP_TIMEOUT", "10s")
+	c.ReadinessTimeout = s.duration("READINESS_TIMEOUT", "2s")
 
-	c.LogLevel = cast.ToString(getOrReturnDefault("LOG_LEVEL", ""))
-	c.HTTPPort = cast.ToString(getOrReturnDefault("HTTP_PORT", ""))
+	c.UserServiceTimeout = s.duration("USER_SERVICE_TIMEOUT", "5s")
+	c.ScheduleServiceTimeout = s.duration("SCHEDULE_SERVICE_TIMEOUT", "5s")
+	c.RPCTimeouts = s.durations("RPC_TIMEOUTS")
+	c.RPCMaxRetries = s.int("RPC_MAX_RETRIES", 2)
+	c.RPCRetryBaseDelay = s.duration("RPC_RETRY_BASE_DELAY", "100ms")
+	c.RPCRetryMaxDelay = s.duration("RPC_RETRY_MAX_DELAY", "1s")
 
-	c.UserServiceHost = cast.ToString(getOrReturnDefault("USER_SERVICE_HOST", ""))
-	c.UserServicePort = cast.ToString(getOrReturnDefault("USER_GRPC_PORT", ""))
-	c.ScheduleServiceHost = cast.ToString(getOrReturnDefault("SCHEDULE_SERVICE_HOST", ""))
-	c.ScheduleServicePort = cast.ToString(getOrReturnDefault("SCHEDULE_GRPC_PORT", ""))
+	c.BreakerFailureThreshold = s.int("BREAKER_FAILURE_THRESHOLD", 5)
+	c.BreakerOpenTimeout = s.duration("BREAKER_OPEN_TIMEOUT", "30s")
+
+	c.HTTPReadTimeout = s.duration("HTTP_READ_TIMEOUT", "15s")
+	c.HTTPReadHeaderTimeout = s.duration("HTTP_READ_HEADER_TIMEOUT", "5s")
+	c.HTTPWriteTimeout = s.duration("HTTP_WRITE_TIMEOUT", "30s")
+	c.HTTPIdleTimeout = s.duration("HTTP_IDLE_TIMEOUT", "60s")
+	c.ShutdownDelay = s.duration("SHUTDOWN_DELAY", "0s")
+	c.ShutdownTimeout = s.duration("SHUTDOWN_TIMEOUT", "30s")
+
+	c.UserServiceTLS = loadTLS(s, "USER_SERVICE")
+	c.ScheduleServiceTLS = loadTLS(s, "SCHEDULE_SERVICE")
+	c.HTTPTLS = loadTLS(s, "HTTP")
+	c.TLSReloadInterval = s.duration("TLS_RELOAD_INTERVAL", "30s")
+
+	c.TracingExporter = s.string("TRACING_EXPORTER", "none")
+	c.TracingEndpoint = s.string("TRACING_ENDPOINT", "localhost:4317")
+	c.TracingInsecure = s.bool("TRACING_INSECURE", true)
+	c.TracingSampleRatio = s.float("TRACING_SAMPLE_RATIO", 1.0)
+
+	c.AccessLogSampleRate = s.float("ACCESS_LOG_SAMPLE_RATE", 1.0)
+	c.AccessLogExcludePaths = s.list("ACCESS_LOG_EXCLUDE_PATHS", "/healthz,/readyz,/metrics,/swagger/")
+	c.LogNamespaceLevels = s.string("LOG_NAMESPACE_LEVELS", "")
+	c.LogFile = s.string("LOG_FILE", "")
+	c.LogFileMaxSizeMB = s.int("LOG_FILE_MAX_SIZE_MB", 100)
+	c.LogFileMaxAgeDays = s.int("LOG_FILE_MAX_AGE_DAYS", 7)
+	c.LogFileMaxBackups = s.int("LOG_FILE_MAX_BACKUPS", 5)
+	c.LogFileCompress = s.bool("LOG_FILE_COMPRESS", true)
+
+	c.AccessLogRedactQuery = s.list("ACCESS_LOG_REDACT_QUERY", "token,access_token,refresh_token,password,code,secret")
+
+	s.unknown()
+	c.settings = s.settings
+	c.problems = s.errs
 	return c
 }
 
-func getOrReturnDefault(key string, defaultValue interface{}) interface{} {
-	if os.Getenv(key) == "" {
-		return defaultValue
-	}
-	return os.Getenv(key)
+// loadTLS reads PREFIX_TLS, PREFIX_TLS_CA_FILE, PREFIX_TLS_CERT_FILE, PREFIX_TLS_KEY_FILE
+// and PREFIX_TLS_SERVER_NAME. Setting any of the files turns TLS on.
+func loadTLS(s *source, prefix string) TLS {
+	t := TLS{Enabled: s.bool(prefix+"_TLS", false)}
+	t.CAFile = s.string(prefix+"_TLS_CA_FILE", "")
+	t.CertFile = s.string(prefix+"_TLS_CERT_FILE", "")
+	t.KeyFile = s.string(prefix+"_TLS_KEY_FILE", "")
+	t.ServerName = s.string(prefix+"_TLS_SERVER_NAME", "")
+	t.Enabled = t.Enabled || t.CAFile != "" || t.CertFile != ""
+	return t
+}
+
+// Address joins a host and a port given either as "9101" or ":9101"
+func Address(host, port string) string {
+	return net.JoinHostPort(host, strings.TrimPrefix(port, ":"))
 }
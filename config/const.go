@@ -1,15 +1,15 @@
 package config
 
 const (
-	ERR_INFORMATION     = "The server has received the request and is continuing the process"
-	SUCCESS             = "The request was successful"
-	ERR_REDIRECTION     = "You have been redirected and the completion of the request requires further action"
-	ERR_BADREQUEST      = "Bad request"
-	ERR_INTERNAL_SERVER = "While the request appears to be valid, the server could not complete the request"
-	SmtpServer          = "smtp.gmail.com"
-	SmtpPort            = "587"
-	SmtpUsername        = "jumaniyozovibodulla07@gmail.com"
-	SmtpPassword        = "pntm dene uuvh qavx"
-	TEACHER_TYPE        = "teacher"
-	STUDENT_TYPE        = "student"
-)
\ No newline at end of file
+	ERR_INFORMATION      = "The server has received the request and is continuing the process"
+	SUCCESS              = "The request was successful"
+	ERR_REDIRECTION      = "You have been redirected and the completion of the request requires further action"
+	ERR_BADREQUEST       = "Bad request"
+	ERR_INTERNAL_SERVER  = "While the request appears to be valid, the server could not complete the request"
+	SUPER_ADMIN_TYPE     = "super_admin"
+	ADMIN_TYPE           = "admin"
+	MANAGER_TYPE         = "manager"
+	TEACHER_TYPE         = "teacher"
+	SUPPORT_TEACHER_TYPE = "support_teacher"
+	STUDENT_TYPE         = "student"
+)
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Flags are the command line options of the gateway
+type Flags struct {
+	File        string            // yaml or toml config file, CONFIG_FILE by default
+	PrintConfig bool              // print the effective config and exit
+	Values      map[string]string // -set KEY=VALUE overrides, they win over every other source
+}
+
+// ParseFlags reads the command line, e.g.
+//
+//	gateway -config ./config.yaml -set http_port=8080 -set log_level=debug
+func ParseFlags(args []string) (Flags, error) {
+	f := Flags{Values: map[string]string{}}
+
+	fs := flag.NewFlagSet("crm-api-gateway", flag.ContinueOnError)
+	fs.StringVar(&f.File, "config", os.Getenv("CONFIG_FILE"), "yaml or toml config file")
+	fs.BoolVar(&f.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted and exit")
+	fs.Func("set", "override a setting, KEY=VALUE (repeatable), keys are the environment variable names", func(s string) error {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("expected KEY=VALUE, got %q", s)
+		}
+		f.Values[normalizeKey(key)] = value
+		return nil
+	})
+
+	if err := fs.Parse(args); err != nil {
+		return f, err
+	}
+	if fs.NArg() > 0 {
+		return f, fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	return f, nil
+}
+
+// Print writes the effective configuration, one KEY=value per line with its source
+func (c Config) Print(w io.Writer) error {
+	for _, s := range c.settings {
+		if _, err := fmt.Fprintf(w, "%s=%s\t# %s\n", s.Key, s.Value, s.Source); err != nil {
+			return err
+		}
+	}
+	return nil
+}
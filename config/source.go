@@ -0,0 +1,251 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "REDACTED"
+
+// Setting is the effective value of one configuration key and where it came from
+type Setting struct {
+	Key    string
+	Value  string
+	Source string // default, file, env or flag
+}
+
+// layer is one source of raw values, keyed by the environment variable name
+type layer struct {
+	name   string
+	lookup func(key string) (string, bool)
+}
+
+// source resolves keys through its layers, highest precedence first, and remembers
+// every key it was asked for so that the effective config can be printed and
+// unknown keys of the config file reported
+type source struct {
+	layers   []layer
+	file     map[string]string
+	flags    map[string]string
+	settings []Setting
+	read     map[string]bool
+	errs     []error
+}
+
+func newSource(file, flags map[string]string) *source {
+	s := &source{file: file, flags: flags, read: map[string]bool{}}
+	s.layers = []layer{
+		{name: "flag", lookup: mapLookup(flags)},
+		{name: "env", lookup: func(key string) (string, bool) {
+			v := os.Getenv(key)
+			return v, v != ""
+		}},
+		{name: "file", lookup: mapLookup(file)},
+	}
+	return s
+}
+
+func mapLookup(m map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := m[key]
+		return v, ok
+	}
+}
+
+// lookup returns the raw value of key from the first layer that sets it
+func (s *source) lookup(key string) (value, from string, ok bool) {
+	s.read[key] = true
+	for _, l := range s.layers {
+		if v, ok := l.lookup(key); ok {
+			return v, l.name, true
+		}
+	}
+	return "", "", false
+}
+
+func (s *source) get(key, def string) string {
+	value, from, ok := s.lookup(key)
+	if !ok {
+		value, from = def, "default"
+	}
+	s.settings = append(s.settings, Setting{Key: key, Value: value, Source: from})
+	return value
+}
+
+// secret reads key or, when it is not set, the file named by KEY_FILE (docker and
+// kubernetes secrets). Its value is never printed.
+func (s *source) secret(key string) string {
+	value, from, ok := s.lookup(key)
+	if !ok {
+		if path, pathFrom, ok := s.lookup(key + "_FILE"); ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				s.errs = append(s.errs, fmt.Errorf("%s_FILE: %w", key, err))
+			}
+			value, from = strings.TrimSpace(string(data)), pathFrom+" "+key+"_FILE"
+		}
+	}
+	if from == "" {
+		from = "default"
+	}
+
+	shown := ""
+	if value != "" {
+		shown = redacted
+	}
+	s.settings = append(s.settings, Setting{Key: key, Value: shown, Source: from})
+	return value
+}
+
+func (s *source) string(key, def string) string {
+	return strings.TrimSpace(s.get(key, def))
+}
+
+func (s *source) list(key, def string) []string {
+	var items []string
+	for _, item := range strings.Split(s.get(key, def), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (s *source) int(key string, def int) int {
+	raw := s.get(key, strconv.Itoa(def))
+	v, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be an integer, got %q", key, raw))
+		return def
+	}
+	return v
+}
+
+func (s *source) bool(key string, def bool) bool {
+	raw := s.get(key, strconv.FormatBool(def))
+	v, err := strconv.ParseBool(strings.TrimSpace(raw))
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be true or false, got %q", key, raw))
+		return def
+	}
+	return v
+}
+
+func (s *source) float(key string, def float64) float64 {
+	raw := s.get(key, strconv.FormatFloat(def, 'f', -1, 64))
+	v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be a number, got %q", key, raw))
+		return def
+	}
+	return v
+}
+
+func (s *source) duration(key, def string) time.Duration {
+	raw := s.get(key, def)
+	v, err := time.ParseDuration(strings.TrimSpace(raw))
+	if err != nil {
+		s.errs = append(s.errs, fmt.Errorf("%s must be a duration such as 5s or 1m, got %q", key, raw))
+		v, _ = time.ParseDuration(def)
+	}
+	return v
+}
+
+// durations reads "key=duration" pairs separated by commas
+func (s *source) durations(key string) map[string]time.Duration {
+	durations := map[string]time.Duration{}
+	for _, pair := range strings.Split(s.get(key, ""), ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if !ok || err != nil {
+			s.errs = append(s.errs, fmt.Errorf("%s: invalid entry %q, use name=duration", key, pair))
+			continue
+		}
+		durations[strings.TrimSpace(name)] = d
+	}
+	return durations
+}
+
+// unknown reports keys of the config file and flags that no setting asked for, mostly typos
+func (s *source) unknown() {
+	for from, values := range map[string]map[string]string{"config file": s.file, "flag": s.flags} {
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			if !s.read[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			s.errs = append(s.errs, fmt.Errorf("%s: unknown setting %s", from, key))
+		}
+	}
+}
+
+// readFile loads a yaml or toml config file. Keys are the environment variable names
+// in any case, nested tables are joined with "_" (user_service: {host: x} is USER_SERVICE_HOST).
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", raw, values)
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]interface{}, values map[string]string) {
+	for k, v := range raw {
+		key := normalizeKey(k)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch v := v.(type) {
+		case map[string]interface{}:
+			flatten(key, v, values)
+		case []interface{}:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// normalizeKey turns "http-port", "http.port" and "http_port" into HTTP_PORT
+func normalizeKey(key string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(strings.TrimSpace(key)))
+}
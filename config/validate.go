@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate reports every problem of the configuration at once
+func (c Config) Validate() error {
+	errs := append([]error(nil), c.problems...)
+
+	switch c.Environment {
+	case "develop", "staging", "production":
+	default:
+		errs = append(errs, fmt.Errorf("ENVIRONMENT must be develop, staging or production, got %q", c.Environment))
+	}
+	switch c.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, fmt.Errorf("LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel))
+	}
+	if err := validatePort("HTTP_PORT", c.HTTPPort); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.SecretKey == "" {
+		errs = append(errs, errors.New("SECRET_KEY is not set, tokens cannot be signed"))
+	}
+	if c.UserServiceHost == "" {
+		errs = append(errs, errors.New("USER_SERVICE_HOST is not set"))
+	}
+	if err := validatePort("USER_GRPC_PORT", c.UserServicePort); err != nil {
+		errs = append(errs, err)
+	}
+	if c.ScheduleServiceHost == "" {
+		errs = append(errs, errors.New("SCHEDULE_SERVICE_HOST is not set"))
+	}
+	if err := validatePort("SCHEDULE_GRPC_PORT", c.ScheduleServicePort); err != nil {
+		errs = append(errs, err)
+	}
+	if c.RBACPolicyPath == "" {
+		errs = append(errs, errors.New("RBAC_POLICY_PATH is not set"))
+	}
+
+	switch c.DefaultLanguage {
+	case "uz", "ru", "en":
+	default:
+		errs = append(errs, fmt.Errorf("DEFAULT_LANGUAGE must be uz, ru or en, got %q", c.DefaultLanguage))
+	}
+
+	if c.DefaultPageSize < 1 || c.DefaultPageSize > c.MaxPageSize {
+		errs = append(errs, fmt.Errorf("DEFAULT_PAGE_SIZE must be between 1 and MAX_PAGE_SIZE (%d), got %d", c.MaxPageSize, c.DefaultPageSize))
+	}
+
+	for name, d := range map[string]time.Duration{
+		"ACCESS_TOKEN_TTL":         c.AccessTokenTTL,
+		"REFRESH_TOKEN_TTL":        c.RefreshTokenTTL,
+		"PASSWORD_CHANGE_WINDOW":   c.PasswordChangeWindow,
+		"BACKEND_STARTUP_TIMEOUT":  c.BackendStartupTimeout,
+		"READINESS_TIMEOUT":        c.ReadinessTimeout,
+		"HTTP_READ_HEADER_TIMEOUT": c.HTTPReadHeaderTimeout,
+		"SHUTDOWN_TIMEOUT":         c.ShutdownTimeout,
+		"TLS_RELOAD_INTERVAL":      c.TLSReloadInterval,
+	} {
+		if d <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be positive, got %s", name, d))
+		}
+	}
+	for name, n := range map[string]int{
+		"RPC_MAX_RETRIES":           c.RPCMaxRetries,
+		"BREAKER_FAILURE_THRESHOLD": c.BreakerFailureThreshold,
+		"LOG_FILE_MAX_SIZE_MB":      c.LogFileMaxSizeMB,
+		"LOG_FILE_MAX_AGE_DAYS":     c.LogFileMaxAgeDays,
+		"LOG_FILE_MAX_BACKUPS":      c.LogFileMaxBackups,
+	} {
+		if n < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", name, n))
+		}
+	}
+	if c.PasswordChangeMaxAttempts < 1 {
+		errs = append(errs, fmt.Errorf("PASSWORD_CHANGE_MAX_ATTEMPTS must be at least 1, got %d", c.PasswordChangeMaxAttempts))
+	}
+	if c.ShutdownDelay < 0 {
+		errs = append(errs, fmt.Errorf("SHUTDOWN_DELAY must not be negative, got %s", c.ShutdownDelay))
+	}
+	if c.BackendStartupMode != "fail" && c.BackendStartupMode != "degraded" {
+		errs = append(errs, fmt.Errorf("BACKEND_STARTUP_MODE must be fail or degraded, got %q", c.BackendStartupMode))
+	}
+
+	for name, t := range map[string]TLS{"USER_SERVICE": c.UserServiceTLS, "SCHEDULE_SERVICE": c.ScheduleServiceTLS, "HTTP": c.HTTPTLS} {
+		if (t.CertFile == "") != (t.KeyFile == "") {
+			errs = append(errs, fmt.Errorf("%s_TLS_CERT_FILE and %s_TLS_KEY_FILE must be set together", name, name))
+		}
+	}
+	if c.HTTPTLS.Enabled && c.HTTPTLS.CertFile == "" {
+		errs = append(errs, errors.New("HTTP_TLS needs HTTP_TLS_CERT_FILE and HTTP_TLS_KEY_FILE"))
+	}
+
+	switch c.TracingExporter {
+	case "none", "otlp", "stdout":
+	default:
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER must be none, otlp or stdout, got %q", c.TracingExporter))
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", c.TracingSampleRatio))
+	}
+
+	if c.AccessLogSampleRate < 0 || c.AccessLogSampleRate > 1 {
+		errs = append(errs, fmt.Errorf("ACCESS_LOG_SAMPLE_RATE must be between 0 and 1, got %v", c.AccessLogSampleRate))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validatePort accepts "8080" as well as the older ":8080" form
+func validatePort(name, port string) error {
+	if port == "" {
+		return fmt.Errorf("%s is not set", name)
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(port, ":"))
+	if err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("%s must be a port between 1 and 65535, got %q", name, port)
+	}
+	return nil
+}
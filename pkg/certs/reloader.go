@@ -0,0 +1,234 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Reloader keeps a certificate pair and a CA bundle in memory and reloads them
+// when the files on disk change, so rotated certificates are picked up without a restart.
+// Every file is optional: without a CA bundle the system roots are used, without
+// a pair no certificate is presented.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	onError  func(error)
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	pool     *x509.CertPool
+	modTimes map[string]time.Time
+
+	done chan struct{}
+	once sync.Once
+}
+
+// NewReloader loads the files and polls them every interval, interval 0 disables reloading.
+// onError is called when a reload fails, the previous certificates stay in use.
+func NewReloader(certFile, keyFile, caFile string, interval time.Duration, onError func(error)) (*Reloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("certificate and key files must be set together")
+	}
+
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		onError:  onError,
+		modTimes: map[string]time.Time{},
+		done:     make(chan struct{}),
+	}
+
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+
+	if interval > 0 {
+		go r.watch(interval)
+	}
+
+	return r, nil
+}
+
+// Close stops watching the files
+func (r *Reloader) Close() {
+	r.once.Do(func() { close(r.done) })
+}
+
+func (r *Reloader) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.reload(); err != nil && r.onError != nil {
+				r.onError(err)
+			}
+		}
+	}
+}
+
+func (r *Reloader) files() []string {
+	var files []string
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
+
+func (r *Reloader) changed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err == nil && !info.ModTime().Equal(r.modTimes[f]) {
+			return true
+		}
+	}
+	return false
+}
+
+// reload loads the files again. A failed reload still remembers the modification
+// times so that a broken file is reported once and retried on its next change.
+func (r *Reloader) reload() error {
+	modTimes := map[string]time.Time{}
+	for _, f := range r.files() {
+		info, err := os.Stat(f)
+		if err != nil {
+			return err
+		}
+		modTimes[f] = info.ModTime()
+	}
+
+	cert, pool, err := r.load()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.modTimes = modTimes
+	if err != nil {
+		return err
+	}
+	r.cert, r.pool = cert, pool
+
+	return nil
+}
+
+func (r *Reloader) load() (*tls.Certificate, *x509.CertPool, error) {
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		pair, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load key pair %s: %w", r.certFile, err)
+		}
+		cert = &pair
+	}
+
+	var pool *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no certificates found in %s", r.caFile)
+		}
+	}
+
+	return cert, pool, nil
+}
+
+func (r *Reloader) certificate() *tls.Certificate {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert
+}
+
+func (r *Reloader) roots() *x509.CertPool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.pool
+}
+
+// ClientConfig is a client side config presenting the current certificate (mutual TLS)
+// and verifying the server against the current CA bundle
+func (r *Reloader) ClientConfig(serverName string) *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+	}
+
+	if r.certFile != "" {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.certificate(), nil
+		}
+	}
+
+	if r.caFile != "" {
+		// the standard verification reads RootCAs once, so it is replaced by
+		// an equivalent check against the pool loaded last
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return r.verify(cs, x509.ExtKeyUsageServerAuth)
+		}
+	}
+
+	return cfg
+}
+
+// ServerConfig is a server side config serving the current certificate. With a CA
+// bundle clients must present a certificate signed by it.
+func (r *Reloader) ServerConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return r.certificate(), nil
+		},
+	}
+
+	if r.caFile != "" {
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		cfg.VerifyConnection = func(cs tls.ConnectionState) error {
+			return r.verify(cs, x509.ExtKeyUsageClientAuth)
+		}
+	}
+
+	return cfg
+}
+
+func (r *Reloader) verify(cs tls.ConnectionState, usage x509.ExtKeyUsage) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("peer presented no certificate")
+	}
+
+	opts := x509.VerifyOptions{
+		Roots:         r.roots(),
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{usage},
+	}
+	if usage == x509.ExtKeyUsageServerAuth {
+		opts.DNSName = cs.ServerName
+	}
+	for _, c := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
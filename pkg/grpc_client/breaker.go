@@ -0,0 +1,159 @@
+package grpc_client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// BreakerState ...
+type BreakerState int
+
+const (
+	// BreakerClosed lets every call through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects calls without reaching the backend
+	BreakerOpen
+	// BreakerHalfOpen lets a single probe through to find out whether the backend recovered
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	}
+	return "closed"
+}
+
+// breakerFailures are the codes that mean the backend itself is in trouble,
+// application errors such as NotFound keep the breaker closed
+var breakerFailures = map[codes.Code]bool{
+	codes.Unavailable:      true,
+	codes.DeadlineExceeded: true,
+}
+
+// CircuitOpenError is returned instead of calling a backend whose breaker is open.
+// It carries the Unavailable gRPC status so it is handled like any other outage.
+type CircuitOpenError struct {
+	Backend    string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s is unavailable, retry in %s", e.Backend, e.RetryAfter.Round(time.Second))
+}
+
+// GRPCStatus ...
+func (e *CircuitOpenError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+// Breaker opens after threshold consecutive failures, stays open for openTimeout
+// and then half-opens to probe the backend with one call
+type Breaker struct {
+	name        string
+	threshold   int
+	openTimeout time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func newBreaker(name string, threshold int, openTimeout time.Duration) *Breaker {
+	return &Breaker{name: name, threshold: threshold, openTimeout: openTimeout}
+}
+
+// State ...
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.openTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// allow reports whether a call may go to the backend
+func (b *Breaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if wait := b.openTimeout - time.Since(b.openedAt); wait > 0 {
+			return &CircuitOpenError{Backend: b.name, RetryAfter: wait}
+		}
+		b.state = BreakerHalfOpen
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probing {
+			return &CircuitOpenError{Backend: b.name, RetryAfter: time.Second}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// record counts the result of a call let through by allow
+func (b *Breaker) record(err error) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if !breakerFailures[status.Code(err)] {
+		b.state, b.failures = BreakerClosed, 0
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state, b.openedAt = BreakerOpen, time.Now()
+	}
+}
+
+// breakerInterceptor fails fast while the breaker of the backend is open.
+// Health checks bypass it so that readiness reflects the backend and not the breaker.
+func breakerInterceptor(b *Breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if method == healthpb.Health_Check_FullMethodName {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		if err := b.allow(); err != nil {
+			return err
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if status.Code(err) == codes.Canceled && ctx.Err() != nil {
+			// the client went away, this says nothing about the backend
+			b.mu.Lock()
+			b.probing = false
+			b.mu.Unlock()
+			return err
+		}
+
+		b.record(err)
+		return err
+	}
+}
@@ -16,11 +16,17 @@ import (
 	ss "crmapi/genproto/user_service/support_teachers"
 	ts "crmapi/genproto/user_service/teachers"
 	"fmt"
+	"time"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/grpc/health" // client side health checking
 
 	"crmapi/config"
+	"crmapi/pkg/certs"
+	"crmapi/pkg/logger"
 )
 
 // GrpcClientI ...
@@ -42,6 +48,8 @@ type GrpcClientI interface {
 }
 
 type GrpcClient struct {
+	backends []*backend
+
 	superAdminService sp.SuperAdminServiceClient
 	bracnesService    br.BranchesServiceClient
 	adminsService     ad.AdministratorsServiceClient // delete, getall
@@ -59,151 +67,85 @@ type GrpcClient struct {
 	attendacesService     at.AttendanceServiceClient
 }
 
-// New ...
-func New(cfg config.Config) (*GrpcClient, error) {
-
-	superAdminService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	branchesService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	adminService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
-	if err != nil {
-		return nil, err
-	}
+// New opens one connection per backend and builds every service client on top of it.
+// Connections are established lazily, use CheckHealth to find out whether the backends are up.
+func New(cfg config.Config, log logger.Logger) (*GrpcClient, error) {
 
-	eventsService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
-	if err != nil {
-		return nil, err
+	retry := RetryPolicy{
+		MaxRetries: cfg.RPCMaxRetries,
+		BaseDelay:  cfg.RPCRetryBaseDelay,
+		MaxDelay:   cfg.RPCRetryMaxDelay,
 	}
 
-	groupsService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
+	user, err := newBackend(UserService, config.Address(cfg.UserServiceHost, cfg.UserServicePort), cfg.UserServiceTLS,
+		Timeouts{Default: cfg.UserServiceTimeout, Methods: cfg.RPCTimeouts}, retry, cfg, log)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%s: %w", UserService, err)
 	}
 
-	joinEventsService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
+	schedule, err := newBackend(ScheduleService, config.Address(cfg.ScheduleServiceHost, cfg.ScheduleServicePort), cfg.ScheduleServiceTLS,
+		Timeouts{Default: cfg.ScheduleServiceTimeout, Methods: cfg.RPCTimeouts}, retry, cfg, log)
 	if err != nil {
-		return nil, err
+		user.close()
+		return nil, fmt.Errorf("%s: %w", ScheduleService, err)
 	}
 
-	managersService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
-	if err != nil {
-		return nil, err
-	}
+	userConn, scheduleConn := user.conn, schedule.conn
 
-	studentsService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	supportTeachersService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	teachersService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	scheduleService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.ScheduleServiceHost, cfg.ScheduleServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
-	if err != nil {
-		return nil, err
-	}
+	return &GrpcClient{
+		backends: []*backend{user, schedule},
+
+		superAdminService:     sp.NewSuperAdminServiceClient(userConn),
+		bracnesService:        br.NewBranchesServiceClient(userConn),
+		adminsService:         ad.NewAdministratorsServiceClient(userConn),
+		eventsService:         ev.NewEventServiceClient(userConn),
+		groupsService:         gr.NewGroupsServiceClient(userConn),
+		joinEventsService:     ej.NewJoinEventServiceClient(userConn),
+		managersService:       mn.NewManagersServiceClient(userConn),
+		studentsService:       st.NewStudentServiceClient(userConn),
+		supportTeacherService: ss.NewSupportTeacherServiceClient(userConn),
+		teachersService:       ts.NewTeacherServiceClient(userConn),
+		schedulesService:      sc.NewScheduleServiceClient(scheduleConn),
+		tasksService:          tk.NewTaskServiceClient(scheduleConn),
+		lessonsService:        ls.NewLesssonServiceClient(scheduleConn),
+		attendacesService:     at.NewAttendanceServiceClient(scheduleConn),
+	}, nil
+}
 
-	taskService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.ScheduleServiceHost, cfg.ScheduleServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+// healthServiceConfig turns on client side health checking, so a backend reporting
+// NOT_SERVING is not picked and calls fail fast with Unavailable instead of hanging.
+// Client side health checking needs a balancer that supports it, hence round_robin.
+const healthServiceConfig = `{
+	"loadBalancingConfig": [{"round_robin": {}}],
+	"healthCheckConfig": {"serviceName": ""}
+}`
+
+func dial(address string, creds credentials.TransportCredentials, interceptors ...grpc.UnaryClientInterceptor) (*grpc.ClientConn, error) {
+	return grpc.NewClient(
+		address,
+		grpc.WithTransportCredentials(creds),
+		// client spans for every call, the trace context travels to the backend in the metadata
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithDefaultServiceConfig(healthServiceConfig),
+		grpc.WithChainUnaryInterceptor(interceptors...),
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
 	)
-	if err != nil {
-		return nil, err
-	}
+}
 
-	lessonsService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.ScheduleServiceHost, cfg.ScheduleServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
-	if err != nil {
-		return nil, err
+// transportCredentials returns TLS credentials reloaded from disk when the backend has TLS enabled
+func transportCredentials(name string, t config.TLS, interval time.Duration, log logger.Logger) (credentials.TransportCredentials, *certs.Reloader, error) {
+	if !t.Enabled {
+		return insecure.NewCredentials(), nil, nil
 	}
 
-	attendancesService, err := grpc.Dial(
-		fmt.Sprintf("%s%s", cfg.ScheduleServiceHost, cfg.ScheduleServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(52428800), grpc.MaxCallSendMsgSize(52428800)),
-	)
+	reloader, err := certs.NewReloader(t.CertFile, t.KeyFile, t.CAFile, interval, func(err error) {
+		log.Error("error while reloading backend certificates", logger.String("backend", name), logger.Error(err))
+	})
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return &GrpcClient{
-		superAdminService:     sp.NewSuperAdminServiceClient(superAdminService),
-		bracnesService:        br.NewBranchesServiceClient(branchesService),
-		adminsService:         ad.NewAdministratorsServiceClient(adminService),
-		eventsService:         ev.NewEventServiceClient(eventsService),
-		groupsService:         gr.NewGroupsServiceClient(groupsService),
-		joinEventsService:     ej.NewJoinEventServiceClient(joinEventsService),
-		managersService:       mn.NewManagersServiceClient(managersService),
-		studentsService:       st.NewStudentServiceClient(studentsService),
-		supportTeacherService: ss.NewSupportTeacherServiceClient(supportTeachersService),
-		teachersService:       ts.NewTeacherServiceClient(teachersService),
-		schedulesService:      sc.NewScheduleServiceClient(scheduleService),
-		tasksService:          tk.NewTaskServiceClient(taskService),
-		lessonsService:        ls.NewLesssonServiceClient(lessonsService),
-		attendacesService:     at.NewAttendanceServiceClient(attendancesService),
-	}, nil
+	return credentials.NewTLS(reloader.ClientConfig(t.ServerName)), reloader, nil
 }
 
 func (g *GrpcClient) SuperAdmins() sp.SuperAdminServiceClient {
@@ -0,0 +1,155 @@
+package grpc_client
+
+import (
+	"context"
+	"crmapi/config"
+	"crmapi/pkg/certs"
+	"crmapi/pkg/logger"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// UserService ...
+	UserService = "user_service"
+	// ScheduleService ...
+	ScheduleService = "schedule_service"
+)
+
+// backend is one managed connection shared by all clients of a service
+type backend struct {
+	name    string
+	conn    *grpc.ClientConn
+	health  healthpb.HealthClient
+	breaker *Breaker
+	certs   *certs.Reloader
+}
+
+func newBackend(name, address string, t config.TLS, timeouts Timeouts, retry RetryPolicy, cfg config.Config, log logger.Logger) (*backend, error) {
+	creds, reloader, err := transportCredentials(name, t, cfg.TLSReloadInterval, log)
+	if err != nil {
+		return nil, err
+	}
+
+	breaker := newBreaker(name, cfg.BreakerFailureThreshold, cfg.BreakerOpenTimeout)
+
+	// metrics and the breaker see one result per call and the deadline covers all of its attempts,
+	// health probes are kept out of the call metrics and never retried
+	conn, err := dial(address, creds, requestIDInterceptor(), skipProbes(metricsInterceptor(name)),
+		breakerInterceptor(breaker), timeoutInterceptor(timeouts), skipProbes(retryInterceptor(retry)))
+	if err != nil {
+		if reloader != nil {
+			reloader.Close()
+		}
+		return nil, err
+	}
+
+	return &backend{
+		name:    name,
+		conn:    conn,
+		health:  healthpb.NewHealthClient(conn),
+		breaker: breaker,
+		certs:   reloader,
+	}, nil
+}
+
+func (b *backend) close() error {
+	if b.certs != nil {
+		b.certs.Close()
+	}
+	return b.conn.Close()
+}
+
+// BackendStatus is the result of a health check of one backend
+type BackendStatus struct {
+	Name    string        `json:"name"`
+	Target  string        `json:"target"`
+	State   string        `json:"state"`
+	Circuit string        `json:"circuit"`
+	Status  string        `json:"status"`
+	Serving bool          `json:"serving"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// States returns the connectivity state of every backend connection
+func (g *GrpcClient) States() map[string]connectivity.State {
+	states := make(map[string]connectivity.State, len(g.backends))
+	for _, b := range g.backends {
+		states[b.name] = b.conn.GetState()
+	}
+	return states
+}
+
+// Breakers returns the circuit breaker state of every backend
+func (g *GrpcClient) Breakers() map[string]BreakerState {
+	states := make(map[string]BreakerState, len(g.backends))
+	for _, b := range g.backends {
+		states[b.name] = b.breaker.State()
+	}
+	return states
+}
+
+// CheckHealth runs the gRPC health protocol against every backend concurrently.
+// Backends that do not implement the protocol are treated as serving once connected.
+// With wait set the checks block until the connection is ready or ctx is done.
+func (g *GrpcClient) CheckHealth(ctx context.Context, wait bool) []BackendStatus {
+	statuses := make([]BackendStatus, len(g.backends))
+
+	var wg sync.WaitGroup
+	for i, b := range g.backends {
+		wg.Add(1)
+		go func(i int, b *backend) {
+			defer wg.Done()
+			statuses[i] = b.check(ctx, wait)
+		}(i, b)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+func (b *backend) check(ctx context.Context, wait bool) BackendStatus {
+	start := time.Now()
+	resp, err := b.health.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(wait))
+
+	result := BackendStatus{
+		Name:    b.name,
+		Target:  b.conn.Target(),
+		Latency: time.Since(start),
+	}
+
+	switch {
+	case err == nil:
+		result.Status = resp.GetStatus().String()
+		result.Serving = resp.GetStatus() == healthpb.HealthCheckResponse_SERVING
+	case status.Code(err) == codes.Unimplemented:
+		result.Status = healthpb.HealthCheckResponse_SERVING.String()
+		result.Serving = true
+	default:
+		result.Status = healthpb.HealthCheckResponse_UNKNOWN.String()
+		result.Error = err.Error()
+	}
+	result.State = b.conn.GetState().String()
+	result.Circuit = b.breaker.State().String()
+
+	return result
+}
+
+// Close closes every backend connection
+func (g *GrpcClient) Close() error {
+	var errs []error
+	for _, b := range g.backends {
+		if err := b.close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
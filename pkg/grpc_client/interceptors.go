@@ -0,0 +1,154 @@
+package grpc_client
+
+import (
+	"context"
+	"crmapi/pkg/metrics"
+	"crmapi/pkg/requestid"
+	"math/rand"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// idempotentMethods may be sent again after a failure without side effects
+var idempotentMethods = map[string]bool{
+	"GetById": true,
+	"GetAll":  true,
+}
+
+// retryableCodes are the failures worth another attempt
+var retryableCodes = map[codes.Code]bool{
+	codes.Unavailable:       true,
+	codes.ResourceExhausted: true,
+	codes.Aborted:           true,
+}
+
+// Timeouts holds the deadline of a backend call: the per-method override when
+// one matches, otherwise the backend default
+type Timeouts struct {
+	Default time.Duration
+	// Methods is keyed by full method ("/user_service.StudentService/GetAll"),
+	// service and method ("StudentService/GetAll") or only the method ("GetAll")
+	Methods map[string]time.Duration
+}
+
+func (t Timeouts) forMethod(fullMethod string) time.Duration {
+	service, method := splitMethod(fullMethod)
+	if i := strings.LastIndex(service, "."); i >= 0 {
+		service = service[i+1:]
+	}
+
+	for _, key := range []string{fullMethod, service + "/" + method, method} {
+		if d, ok := t.Methods[key]; ok {
+			return d
+		}
+	}
+	return t.Default
+}
+
+// RetryPolicy configures retries of idempotent calls
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// backoff returns a full jitter exponential delay for the given retry (0 based)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay << retry
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// timeoutInterceptor bounds every call by its configured deadline unless the caller already set an earlier one
+func timeoutInterceptor(t Timeouts) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if d := t.forMethod(method); d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// retryInterceptor retries idempotent calls that failed with a retryable code,
+// sleeping a jittered exponential backoff between attempts. Writes are never retried.
+func retryInterceptor(p RetryPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		if _, name := splitMethod(method); !idempotentMethods[name] {
+			return err
+		}
+
+		for retry := 0; retry < p.MaxRetries && err != nil && retryableCodes[status.Code(err)]; retry++ {
+			timer := time.NewTimer(p.backoff(retry))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+
+			err = invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		return err
+	}
+}
+
+// splitMethod splits "/package.Service/Method" into "package.Service" and "Method"
+func splitMethod(fullMethod string) (service, method string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "", fullMethod
+}
+
+// skipProbes bypasses interceptor for the calls of the health service, whose probes
+// would otherwise be counted and timed like backend traffic
+func skipProbes(interceptor grpc.UnaryClientInterceptor) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if service, _ := splitMethod(method); service == healthpb.Health_ServiceDesc.ServiceName {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		return interceptor(ctx, method, req, reply, cc, invoker, opts...)
+	}
+}
+
+// requestIDInterceptor forwards the request id of the http request to the backend as metadata
+func requestIDInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := requestid.FromContext(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, requestid.MetadataKey, id)
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// metricsInterceptor records the latency and the final code of every call of a backend
+func metricsInterceptor(backend string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		service, name := splitMethod(method)
+		code := status.Code(err).String()
+		metrics.GRPCCalls.WithLabelValues(backend, service, name, code).Inc()
+		metrics.GRPCDuration.WithLabelValues(backend, service, name, code).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
@@ -0,0 +1,148 @@
+package i18n
+
+const (
+	// KeySuccess ...
+	KeySuccess = "SUCCESS"
+	// KeyInformation ...
+	KeyInformation = "INFORMATION"
+	// KeyRedirection ...
+	KeyRedirection = "REDIRECTION"
+	// RulePrefix prefixes the keys of validation rule messages, e.g. "rule.required"
+	RulePrefix = "rule."
+)
+
+// catalog holds the messages of every supported language. Error keys are the
+// handler ErrorCode* values, rule keys are RulePrefix + the validator rule name.
+var catalog = map[string]map[string]string{
+	English: {
+		KeySuccess:     "The request was successful",
+		KeyInformation: "The server has received the request and is continuing the process",
+		KeyRedirection: "You have been redirected and the completion of the request requires further action",
+
+		"INVALID_URL":         "Invalid URL parameter",
+		"INVALID_JSON":        "Invalid request body",
+		"INTERNAL":            "Internal server error",
+		"UNAUTHORIZED":        "Unauthorized",
+		"ALREADY_EXISTS":      "Already exists",
+		"NOT_FOUND":           "Not found",
+		"INVALID_CODE":        "Invalid or expired code",
+		"BAD_REQUEST":         "Bad request",
+		"FORBIDDEN":           "Forbidden",
+		"NOT_APPROVED":        "Not approved",
+		"WRONG_CLUB":          "The record belongs to another branch",
+		"PASSWORDS_NOT_EQUAL": "Passwords are not equal",
+		"TIMEOUT":             "The service did not respond in time",
+		"CANCELED":            "The request was canceled",
+		"UNAVAILABLE":         "The service is temporarily unavailable",
+		"TOO_MANY_REQUESTS":   "Too many requests",
+		"FAILED_PRECONDITION": "The request cannot be applied to the current state",
+		"CONFLICT":            "Conflict with the current state",
+		"NOT_IMPLEMENTED":     "Not implemented",
+
+		RulePrefix + "required":      "is required",
+		RulePrefix + "min":           "must be at least %v",
+		RulePrefix + "max":           "must be at most %v",
+		RulePrefix + "range":         "must be between %v and %v",
+		RulePrefix + "length":        "length must be %d to %d characters",
+		RulePrefix + "uuid":          "must be a valid uuid",
+		RulePrefix + "enum":          "must be a known value",
+		RulePrefix + "enum_values":   "must be one of %s",
+		RulePrefix + "time":          "must be a time in format %s",
+		RulePrefix + "full_name":     "must contain first and last name",
+		RulePrefix + "phone":         "must be in format +998XXXXXXXXX",
+		RulePrefix + "password":      "must be 8 to 30 characters of letters, digits and @ $ _ . #",
+		RulePrefix + "working_day":   "must be a weekday name or a 2006-01-02 date, not sunday",
+		RulePrefix + "unknown_field": "%s is not a field of this resource",
+		RulePrefix + "expand_max":    "can embed at most %d related records, ask for a smaller page",
+		RulePrefix + "bool":          "must be true or false",
+		RulePrefix + "number":        "must be a number",
+	},
+	Uzbek: {
+		KeySuccess:     "So'rov muvaffaqiyatli bajarildi",
+		KeyInformation: "Server so'rovni qabul qildi va uni bajarishda davom etmoqda",
+		KeyRedirection: "Siz yo'naltirildingiz, so'rovni yakunlash uchun qo'shimcha amal talab qilinadi",
+
+		"INVALID_URL":         "URL parametri noto'g'ri",
+		"INVALID_JSON":        "So'rov tanasi noto'g'ri",
+		"INTERNAL":            "Serverda ichki xatolik",
+		"UNAUTHORIZED":        "Avtorizatsiyadan o'tilmagan",
+		"ALREADY_EXISTS":      "Allaqachon mavjud",
+		"NOT_FOUND":           "Topilmadi",
+		"INVALID_CODE":        "Kod noto'g'ri yoki muddati o'tgan",
+		"BAD_REQUEST":         "Noto'g'ri so'rov",
+		"FORBIDDEN":           "Ruxsat berilmagan",
+		"NOT_APPROVED":        "Tasdiqlanmagan",
+		"WRONG_CLUB":          "Yozuv boshqa filialga tegishli",
+		"PASSWORDS_NOT_EQUAL": "Parollar mos kelmadi",
+		"TIMEOUT":             "Xizmat o'z vaqtida javob bermadi",
+		"CANCELED":            "So'rov bekor qilindi",
+		"UNAVAILABLE":         "Xizmat vaqtincha ishlamayapti",
+		"TOO_MANY_REQUESTS":   "So'rovlar soni juda ko'p",
+		"FAILED_PRECONDITION": "So'rovni joriy holatga qo'llab bo'lmaydi",
+		"CONFLICT":            "Joriy holat bilan ziddiyat",
+		"NOT_IMPLEMENTED":     "Amalga oshirilmagan",
+
+		RulePrefix + "required":      "majburiy maydon",
+		RulePrefix + "regex":         "formati noto'g'ri",
+		RulePrefix + "min":           "kamida %v bo'lishi kerak",
+		RulePrefix + "max":           "ko'pi bilan %v bo'lishi kerak",
+		RulePrefix + "range":         "%v va %v oralig'ida bo'lishi kerak",
+		RulePrefix + "length":        "uzunligi %d dan %d gacha belgi bo'lishi kerak",
+		RulePrefix + "uuid":          "to'g'ri uuid bo'lishi kerak",
+		RulePrefix + "enum":          "ma'lum qiymat bo'lishi kerak",
+		RulePrefix + "enum_values":   "quyidagilardan biri bo'lishi kerak: %s",
+		RulePrefix + "time":          "vaqt %s formatida bo'lishi kerak",
+		RulePrefix + "full_name":     "ism va familiyadan iborat bo'lishi kerak",
+		RulePrefix + "phone":         "+998XXXXXXXXX formatida bo'lishi kerak",
+		RulePrefix + "password":      "harflar, raqamlar va @ $ _ . # belgilaridan iborat 8 dan 30 gacha belgi bo'lishi kerak",
+		RulePrefix + "working_day":   "hafta kuni nomi yoki 2006-01-02 formatidagi sana bo'lishi kerak, yakshanba emas",
+		RulePrefix + "unknown_field": "%s bu resursning maydoni emas",
+		RulePrefix + "expand_max":    "ko'pi bilan %d ta bog'liq yozuv qo'shish mumkin, kichikroq sahifa so'rang",
+		RulePrefix + "bool":          "true yoki false bo'lishi kerak",
+		RulePrefix + "number":        "son bo'lishi kerak",
+	},
+	Russian: {
+		KeySuccess:     "Запрос выполнен успешно",
+		KeyInformation: "Сервер получил запрос и продолжает его обработку",
+		KeyRedirection: "Вы были перенаправлены, для завершения запроса требуются дополнительные действия",
+
+		"INVALID_URL":         "Неверный параметр URL",
+		"INVALID_JSON":        "Неверное тело запроса",
+		"INTERNAL":            "Внутренняя ошибка сервера",
+		"UNAUTHORIZED":        "Требуется авторизация",
+		"ALREADY_EXISTS":      "Уже существует",
+		"NOT_FOUND":           "Не найдено",
+		"INVALID_CODE":        "Неверный или просроченный код",
+		"BAD_REQUEST":         "Неверный запрос",
+		"FORBIDDEN":           "Доступ запрещён",
+		"NOT_APPROVED":        "Не подтверждено",
+		"WRONG_CLUB":          "Запись принадлежит другому филиалу",
+		"PASSWORDS_NOT_EQUAL": "Пароли не совпадают",
+		"TIMEOUT":             "Сервис не ответил вовремя",
+		"CANCELED":            "Запрос отменён",
+		"UNAVAILABLE":         "Сервис временно недоступен",
+		"TOO_MANY_REQUESTS":   "Слишком много запросов",
+		"FAILED_PRECONDITION": "Запрос нельзя применить к текущему состоянию",
+		"CONFLICT":            "Конфликт с текущим состоянием",
+		"NOT_IMPLEMENTED":     "Не реализовано",
+
+		RulePrefix + "required":      "обязательное поле",
+		RulePrefix + "regex":         "неверный формат",
+		RulePrefix + "min":           "должно быть не меньше %v",
+		RulePrefix + "max":           "должно быть не больше %v",
+		RulePrefix + "range":         "должно быть от %v до %v",
+		RulePrefix + "length":        "длина должна быть от %d до %d символов",
+		RulePrefix + "uuid":          "должно быть корректным uuid",
+		RulePrefix + "enum":          "должно быть известным значением",
+		RulePrefix + "enum_values":   "должно быть одним из: %s",
+		RulePrefix + "time":          "время должно быть в формате %s",
+		RulePrefix + "full_name":     "должно содержать имя и фамилию",
+		RulePrefix + "phone":         "должно быть в формате +998XXXXXXXXX",
+		RulePrefix + "password":      "должно быть от 8 до 30 символов из букв, цифр и @ $ _ . #",
+		RulePrefix + "working_day":   "должно быть названием дня недели или датой 2006-01-02, кроме воскресенья",
+		RulePrefix + "unknown_field": "%s не является полем этого ресурса",
+		RulePrefix + "expand_max":    "можно встроить не более %d связанных записей, запросите страницу меньше",
+		RulePrefix + "bool":          "должно быть true или false",
+		RulePrefix + "number":        "должно быть числом",
+	},
+}
@@ -0,0 +1,78 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Uzbek ...
+	Uzbek = "uz"
+	// Russian ...
+	Russian = "ru"
+	// English ...
+	English = "en"
+)
+
+// Supported reports whether the catalog has messages for lang
+func Supported(lang string) bool {
+	_, ok := catalog[lang]
+	return ok
+}
+
+// T returns the message stored under key in lang formatted with args.
+// ok is false when neither lang nor English know the key.
+func T(lang, key string, args ...interface{}) (msg string, ok bool) {
+	tmpl, ok := catalog[lang][key]
+	if !ok {
+		tmpl, ok = catalog[English][key]
+	}
+	if !ok {
+		return "", false
+	}
+
+	if len(args) == 0 {
+		return tmpl, true
+	}
+	return fmt.Sprintf(tmpl, args...), true
+}
+
+// Match picks the best supported language from an Accept-Language header,
+// e.g. "ru-RU,ru;q=0.9,en;q=0.8". fallback is returned when nothing matches.
+func Match(acceptLanguage, fallback string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if q > 0 && Supported(base) {
+			candidates = append(candidates, candidate{lang: base, q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return fallback
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	return candidates[0].lang
+}
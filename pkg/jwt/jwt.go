@@ -0,0 +1,56 @@
+package jwt
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// AccessToken ...
+	AccessToken = "access"
+	// RefreshToken ...
+	RefreshToken = "refresh"
+)
+
+// Claims ...
+type Claims struct {
+	UserID    string `json:"user_id"`
+	UserRole  string `json:"user_role"`
+	BranchID  string `json:"branch_id,omitempty"`
+	TokenType string `json:"token_type"`
+	Language  string `json:"language,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GenerateJWT signs claims with HS256 and sets the expiry to now + ttl
+func GenerateJWT(claims Claims, ttl time.Duration, secretKey string) (string, error) {
+	if secretKey == "" {
+		return "", errors.New("jwt secret key is empty")
+	}
+
+	now := time.Now()
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secretKey))
+}
+
+// ExtractClaims validates the signature and expiry of a token and returns its claims
+func ExtractClaims(tokenString string, secretKey string) (*Claims, error) {
+	claims := &Claims{}
+
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(secretKey), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	return claims, nil
+}
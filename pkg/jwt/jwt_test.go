@@ -0,0 +1,83 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const secret = "test-secret"
+
+func TestGenerateAndExtract(t *testing.T) {
+	token, err := GenerateJWT(Claims{UserID: "u1", UserRole: "admin", BranchID: "b1", TokenType: AccessToken, Language: "uz"}, time.Minute, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := ExtractClaims(token, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != "u1" || claims.UserRole != "admin" || claims.BranchID != "b1" || claims.TokenType != AccessToken || claims.Language != "uz" {
+		t.Errorf("claims = %+v, want the generated ones", claims)
+	}
+	if claims.ExpiresAt == nil || claims.IssuedAt == nil {
+		t.Error("want iat and exp to be set")
+	}
+}
+
+func TestGenerateWithoutSecret(t *testing.T) {
+	if _, err := GenerateJWT(Claims{UserID: "u1"}, time.Minute, ""); err == nil {
+		t.Error("want an error for an empty secret key")
+	}
+}
+
+func TestExtractRejects(t *testing.T) {
+	valid, err := GenerateJWT(Claims{UserID: "u1"}, time.Minute, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := GenerateJWT(Claims{UserID: "u1"}, -time.Minute, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherAlg, err := jwt.NewWithClaims(jwt.SigningMethodHS512, Claims{
+		UserID:           "u1",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	noExpiry, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: "u1"}).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, Claims{
+		UserID:           "u1",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))},
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		secret string
+	}{
+		{"wrong secret", valid, "other-secret"},
+		{"expired", expired, secret},
+		{"other algorithm", otherAlg, secret},
+		{"no expiry", noExpiry, secret},
+		{"unsigned", unsigned, secret},
+		{"garbage", "not.a.token", secret},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if claims, err := ExtractClaims(tt.token, tt.secret); err == nil {
+				t.Errorf("ExtractClaims accepted the token, claims = %+v", claims)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Levels holds the global level and optional per-namespace levels of a logger.
+// They can be changed at runtime, a namespace level applies to the named logger
+// and its children ("crm-api-gateway" also covers "crm-api-gateway.grpc").
+type Levels struct {
+	global zap.AtomicLevel
+
+	mu         sync.RWMutex
+	namespaces map[string]zapcore.Level
+}
+
+func newLevels(level zapcore.Level) *Levels {
+	return &Levels{
+		global:     zap.NewAtomicLevelAt(level),
+		namespaces: map[string]zapcore.Level{},
+	}
+}
+
+// ParseLevel is the strict counterpart of the level names in const.go
+func ParseLevel(level string) (zapcore.Level, error) {
+	switch level {
+	case LevelDebug, LevelInfo, LevelWarn, LevelError:
+		return parseLevel(level), nil
+	}
+	return zapcore.InfoLevel, fmt.Errorf("unknown log level %q, use one of debug, info, warn, error", level)
+}
+
+// Level returns the global level
+func (l *Levels) Level() string {
+	return l.global.Level().String()
+}
+
+// SetLevel changes the global level
+func (l *Levels) SetLevel(level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.global.SetLevel(lvl)
+	return nil
+}
+
+// Namespaces returns the per-namespace levels
+func (l *Levels) Namespaces() map[string]string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	namespaces := make(map[string]string, len(l.namespaces))
+	for name, lvl := range l.namespaces {
+		namespaces[name] = lvl.String()
+	}
+	return namespaces
+}
+
+// SetNamespaceLevel overrides the level of a namespace, an empty level removes the override
+func (l *Levels) SetNamespaceLevel(namespace, level string) error {
+	if namespace == "" {
+		return fmt.Errorf("namespace is empty")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if level == "" {
+		delete(l.namespaces, namespace)
+		return nil
+	}
+
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.namespaces[namespace] = lvl
+	return nil
+}
+
+// SetNamespaceLevels reads "namespace=level" pairs separated by commas
+func (l *Levels) SetNamespaceLevels(pairs string) error {
+	for _, pair := range strings.Split(pairs, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		namespace, level, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return fmt.Errorf("invalid namespace level %q, use namespace=level", pair)
+		}
+		if err := l.SetNamespaceLevel(strings.TrimSpace(namespace), strings.TrimSpace(level)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enabled picks the level of the longest namespace matching the logger name
+func (l *Levels) enabled(name string, lvl zapcore.Level) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if len(l.namespaces) > 0 {
+		names := make([]string, 0, len(l.namespaces))
+		for ns := range l.namespaces {
+			if name == ns || strings.HasPrefix(name, ns+".") {
+				names = append(names, ns)
+			}
+		}
+		if len(names) > 0 {
+			sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+			return lvl >= l.namespaces[names[0]]
+		}
+	}
+
+	return l.global.Enabled(lvl)
+}
+
+// levelCore filters the entries of the wrapped core by the current Levels
+type levelCore struct {
+	zapcore.Core
+	levels *Levels
+}
+
+// Enabled lets every level through, the decision needs the logger name and is taken in Check
+func (c *levelCore) Enabled(zapcore.Level) bool {
+	return true
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), levels: c.levels}
+}
+
+func (c *levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.levels.enabled(ent.LoggerName, ent.Level) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
@@ -5,6 +5,7 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 // Field ...
@@ -19,6 +20,8 @@ var (
 	Error = zap.Error
 	// Bool ...
 	Bool = zap.Bool
+	// Duration ...
+	Duration = zap.Duration
 
 	// Any ...
 	Any = zap.Any
@@ -34,7 +37,23 @@ type Logger interface {
 }
 
 type loggerImpl struct {
-	zap *zap.Logger
+	zap    *zap.Logger
+	levels *Levels
+	file   *lumberjack.Logger
+}
+
+// Config ...
+type Config struct {
+	Level           string
+	Namespace       string
+	NamespaceLevels string // namespace=level pairs separated by commas
+
+	// File is an optional log file rotated by size and age
+	File           string
+	FileMaxSizeMB  int
+	FileMaxAgeDays int
+	FileMaxBackups int
+	FileCompress   bool
 }
 
 var (
@@ -42,20 +61,47 @@ var (
 )
 
 // New ...
+// An unknown level falls back to info, use NewWithConfig to get the error instead.
 func New(level string, namespace string) Logger {
-	if level == "" {
-		level = LevelInfo
+	l, err := NewWithConfig(Config{Level: level, Namespace: namespace})
+	if err != nil {
+		l, _ = NewWithConfig(Config{Level: LevelInfo, Namespace: namespace})
+	}
+	return l
+}
+
+// NewWithConfig is New with per-namespace levels and an optional rotated log file
+func NewWithConfig(cfg Config) (Logger, error) {
+	if cfg.Level == "" {
+		cfg.Level = LevelInfo
+	}
+	if _, err := ParseLevel(cfg.Level); err != nil {
+		return nil, err
+	}
+
+	var file *lumberjack.Logger
+	if cfg.File != "" {
+		file = &lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    cfg.FileMaxSizeMB,
+			MaxAge:     cfg.FileMaxAgeDays,
+			MaxBackups: cfg.FileMaxBackups,
+			Compress:   cfg.FileCompress,
+		}
 	}
 
-	logger := loggerImpl{
-		zap: newZapLogger(level, time.RFC3339),
+	logger := loggerImpl{file: file}
+	logger.zap, logger.levels = newZapLoggerWithSinks(cfg.Level, time.RFC3339, file)
+
+	if err := logger.levels.SetNamespaceLevels(cfg.NamespaceLevels); err != nil {
+		return nil, err
 	}
 
-	logger.zap = logger.zap.Named(namespace)
+	logger.zap = logger.zap.Named(cfg.Namespace)
 
 	zap.RedirectStdLog(logger.zap)
 
-	return &logger
+	return &logger, nil
 }
 
 func (l *loggerImpl) Debug(msg string, fields ...Field) {
@@ -95,7 +141,9 @@ func WithFields(l Logger, fields ...Field) Logger {
 	switch v := l.(type) {
 	case *loggerImpl:
 		return &loggerImpl{
-			zap: v.zap.With(fields...),
+			zap:    v.zap.With(fields...),
+			levels: v.levels,
+			file:   v.file,
 		}
 	default:
 		l.Info("logger.WithFields: invalid logger type")
@@ -103,11 +151,25 @@ func WithFields(l Logger, fields ...Field) Logger {
 	}
 }
 
+// GetLevels returns the runtime levels of a logger created by New, nil for other loggers
+func GetLevels(l Logger) *Levels {
+	if v, ok := l.(*loggerImpl); ok {
+		return v.levels
+	}
+	return nil
+}
+
 // Cleanup ...
 func Cleanup(l Logger) error {
 	switch v := l.(type) {
 	case *loggerImpl:
-		return v.zap.Sync()
+		err := v.zap.Sync()
+		if v.file != nil {
+			if closeErr := v.file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
 	default:
 		l.Info("logger.Cleanup: invalid logger type")
 		return nil
@@ -6,18 +6,26 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 func newZapLogger(level, timeFormat string) *zap.Logger {
+	logger, _ := newZapLoggerWithSinks(level, timeFormat, nil)
+	return logger
+}
+
+// newZapLoggerWithSinks writes errors to stderr, everything else to stdout and every entry
+// to file when it is set. The returned Levels control what gets written.
+func newZapLoggerWithSinks(level, timeFormat string, file *lumberjack.Logger) (*zap.Logger, *Levels) {
 
-	globalLevel := parseLevel(level)
+	levels := newLevels(parseLevel(level))
 
 	highPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
 		return lvl >= zapcore.ErrorLevel
 	})
 
 	lowPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-		return lvl >= globalLevel && lvl < zapcore.ErrorLevel
+		return lvl < zapcore.ErrorLevel
 	})
 
 	consoleInfos := zapcore.Lock(os.Stdout)
@@ -33,14 +41,17 @@ func newZapLogger(level, timeFormat string) *zap.Logger {
 	}
 	consoleEncoder := zapcore.NewJSONEncoder(encoderCfg)
 
-	core := zapcore.NewTee(
+	cores := []zapcore.Core{
 		zapcore.NewCore(consoleEncoder, consoleErrors, highPriority),
 		zapcore.NewCore(consoleEncoder, consoleInfos, lowPriority),
-	)
+	}
+	if file != nil {
+		cores = append(cores, zapcore.NewCore(consoleEncoder, zapcore.AddSync(file), zapcore.DebugLevel))
+	}
 
-	logger := zap.New(core)
+	logger := zap.New(&levelCore{Core: zapcore.NewTee(cores...), levels: levels})
 
-	return logger
+	return logger, levels
 }
 
 func customTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "crm_gateway"
+
+// Registry holds every gateway metric plus the go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests counts handled requests by route template and status
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled by the gateway.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration ...
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// HTTPInFlight ...
+	HTTPInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "HTTP requests being served.",
+	}, []string{"method", "route"})
+
+	// GRPCCalls counts backend calls by service, method and gRPC code
+	GRPCCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_calls_total",
+		Help:      "gRPC calls made to the backends.",
+	}, []string{"backend", "service", "method", "code"})
+
+	// GRPCDuration ...
+	GRPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_call_duration_seconds",
+		Help:      "Latency of gRPC calls made to the backends, retries included.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"backend", "service", "method", "code"})
+
+	// StudentsCreated ...
+	StudentsCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "students_created_total",
+		Help:      "Students created through the gateway.",
+	})
+
+	// AttendanceMarked counts attendance records by status
+	AttendanceMarked = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "attendance_marked_total",
+		Help:      "Attendance records marked through the gateway.",
+	}, []string{"status"})
+
+	// Logins counts login attempts by role and result
+	Logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts.",
+	}, []string{"role", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests, HTTPDuration, HTTPInFlight,
+		GRPCCalls, GRPCDuration,
+		StudentsCreated, AttendanceMarked, Logins,
+	)
+}
+
+// Handler serves the registry in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,62 @@
+package otp
+
+import (
+	"sync"
+	"time"
+)
+
+type window struct {
+	end  time.Time
+	hits int
+}
+
+// Limiter allows at most max events per key in fixed windows, e.g. requests per client ip
+type Limiter struct {
+	mu     sync.Mutex
+	window time.Duration
+	max    int
+	hits   map[string]*window
+	now    func() time.Time
+}
+
+// NewLimiter ...
+func NewLimiter(period time.Duration, max int) *Limiter {
+	return &Limiter{
+		window: period,
+		max:    max,
+		hits:   make(map[string]*window),
+		now:    time.Now,
+	}
+}
+
+// Allow counts an event of key and reports whether it is within the limit
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for k, w := range l.hits {
+		if now.After(w.end) {
+			delete(l.hits, k)
+		}
+	}
+
+	w, ok := l.hits[key]
+	if !ok {
+		w = &window{end: now.Add(l.window)}
+		l.hits[key] = w
+	}
+	if w.hits >= l.max {
+		return false
+	}
+	w.hits++
+	return true
+}
+
+// Reset forgets the events of key, e.g. after a successful attempt
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.hits, key)
+}
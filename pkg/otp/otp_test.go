@@ -0,0 +1,41 @@
+package otp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLimiter(time.Minute, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 1; i <= 3; i++ {
+		if !l.Allow("a") {
+			t.Fatalf("event %d of a was refused, want allowed", i)
+		}
+	}
+	if l.Allow("a") {
+		t.Fatal("4th event of a was allowed, want refused")
+	}
+	if !l.Allow("b") {
+		t.Fatal("keys must be counted on their own, b was refused")
+	}
+
+	now = now.Add(time.Minute + time.Second)
+	if !l.Allow("a") {
+		t.Fatal("a was refused after its window ended")
+	}
+}
+
+func TestLimiterReset(t *testing.T) {
+	l := NewLimiter(time.Hour, 1)
+	if !l.Allow("a") || l.Allow("a") {
+		t.Fatal("want the first event allowed and the second refused")
+	}
+
+	l.Reset("a")
+	if !l.Allow("a") {
+		t.Fatal("a was refused after Reset")
+	}
+}
@@ -0,0 +1,102 @@
+package rbac
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SelfPrefix marks a role that may call a route only for its own record,
+// e.g. "self:student" on GET /api/v1/student/:id
+const SelfPrefix = "self:"
+
+// Policy is a declarative table of which roles may call which route.
+// Routes are keyed by "METHOD /gin/route/template", anything not listed is denied.
+type Policy struct {
+	Routes map[string][]string `yaml:"routes"`
+
+	index map[string]map[string]bool
+}
+
+// Decision ...
+type Decision struct {
+	Allowed  bool
+	SelfOnly bool
+}
+
+// Load reads the policy table from a yaml file
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// Parse builds a policy from yaml content
+func Parse(data []byte) (*Policy, error) {
+	p := &Policy{}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+
+	if len(p.Routes) == 0 {
+		return nil, errors.New("rbac policy has no routes")
+	}
+
+	p.index = make(map[string]map[string]bool, len(p.Routes))
+	for route, roles := range p.Routes {
+		method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || method == "" || path == "" {
+			return nil, fmt.Errorf("rbac policy: invalid route %q, expected \"METHOD /path\"", route)
+		}
+
+		key := routeKey(method, strings.TrimSpace(path))
+		if p.index[key] == nil {
+			p.index[key] = make(map[string]bool)
+		}
+		for _, role := range roles {
+			name := strings.TrimPrefix(role, SelfPrefix)
+			selfOnly := name != role
+			if name == "" {
+				return nil, fmt.Errorf("rbac policy: empty role on %q", route)
+			}
+			if prev, ok := p.index[key][name]; ok && prev != selfOnly {
+				return nil, fmt.Errorf("rbac policy: role %q is listed both with and without %q on %q", name, SelfPrefix, route)
+			}
+			p.index[key][name] = selfOnly
+		}
+	}
+
+	return p, nil
+}
+
+// Check tells whether role may call method on the route template.
+// SelfOnly is set when the role is allowed only for its own record.
+func (p *Policy) Check(method, route, role string) Decision {
+	roles, ok := p.index[routeKey(method, route)]
+	if !ok {
+		return Decision{}
+	}
+
+	selfOnly, ok := roles[role]
+	if !ok {
+		return Decision{}
+	}
+
+	return Decision{Allowed: true, SelfOnly: selfOnly}
+}
+
+// Has reports whether the route is present in the table
+func (p *Policy) Has(method, route string) bool {
+	_, ok := p.index[routeKey(method, route)]
+	return ok
+}
+
+func routeKey(method, route string) string {
+	return strings.ToUpper(method) + " " + route
+}
@@ -0,0 +1,71 @@
+package rbac
+
+import (
+	"strings"
+	"testing"
+)
+
+const policy = `
+routes:
+  GET /api/v1/student/:id: [super_admin, admin, "self:student"]
+  post /api/v1/student: [admin]
+`
+
+func TestCheck(t *testing.T) {
+	p, err := Parse([]byte(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		route  string
+		role   string
+		want   Decision
+	}{
+		{"listed role", "GET", "/api/v1/student/:id", "admin", Decision{Allowed: true}},
+		{"self role", "GET", "/api/v1/student/:id", "student", Decision{Allowed: true, SelfOnly: true}},
+		{"role not listed", "GET", "/api/v1/student/:id", "teacher", Decision{}},
+		{"method case", "POST", "/api/v1/student", "admin", Decision{Allowed: true}},
+		{"route not listed", "DELETE", "/api/v1/student/:id", "admin", Decision{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Check(tt.method, tt.route, tt.role); got != tt.want {
+				t.Errorf("Check() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if !p.Has("get", "/api/v1/student/:id") || p.Has("GET", "/api/v1/students") {
+		t.Error("Has() does not match the listed routes")
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want string
+	}{
+		{"no routes", "routes: {}", "no routes"},
+		{"route without path", "routes:\n  GET: [admin]", "invalid route"},
+		{"self and plain grant", "routes:\n  GET /x: [student, \"self:student\"]", "both with and without"},
+		{"plain and self grant", "routes:\n  GET /x: [\"self:student\", student]", "both with and without"},
+		{"grants of one route in two keys", "routes:\n  GET /x: [student]\n  get /x: [\"self:student\"]", "both with and without"},
+		{"empty self role", "routes:\n  GET /x: [\"self:\"]", "empty role"},
+		{"bad yaml", "routes: [", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.yaml))
+			if err == nil {
+				t.Fatal("Parse() accepted the policy, want an error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package sanitize
+
+import (
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Sanitizer removes sensitive fields, e.g. password hashes, from values
+// before they are written to a client or to the logs.
+type Sanitizer struct {
+	fields map[string]bool
+}
+
+// New builds a sanitizer for the given field names. Names are matched
+// case-insensitively against proto field names, json names and map keys.
+func New(fields []string) *Sanitizer {
+	s := &Sanitizer{fields: make(map[string]bool, len(fields))}
+	for _, f := range fields {
+		f = normalize(f)
+		if f != "" {
+			s.fields[f] = true
+		}
+	}
+
+	return s
+}
+
+// Clean returns a copy of data without sensitive fields. The input is never modified.
+func (s *Sanitizer) Clean(data interface{}) interface{} {
+	if s == nil || len(s.fields) == 0 || data == nil {
+		return data
+	}
+
+	switch v := data.(type) {
+	case string, error, bool, int, int32, int64, float32, float64:
+		return data
+	case proto.Message:
+		m := proto.Clone(v)
+		s.cleanMessage(m.ProtoReflect())
+		return m
+	case []byte, json.RawMessage:
+		return data
+	}
+
+	// arbitrary structs and maps are normalized through json so that nested
+	// messages are handled the same way they are serialized
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return data
+	}
+
+	return s.cleanGeneric(generic)
+}
+
+func (s *Sanitizer) cleanMessage(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if s.isSensitive(string(fd.Name())) || s.isSensitive(fd.JSONName()) {
+			m.Clear(fd)
+			return true
+		}
+
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				s.cleanMessage(list.Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				s.cleanMessage(mv.Message())
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			s.cleanMessage(v.Message())
+		}
+
+		return true
+	})
+}
+
+func (s *Sanitizer) cleanGeneric(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if s.isSensitive(k) {
+				delete(t, k)
+				continue
+			}
+			t[k] = s.cleanGeneric(val)
+		}
+		return t
+	case []interface{}:
+		for i := range t {
+			t[i] = s.cleanGeneric(t[i])
+		}
+		return t
+	}
+
+	return v
+}
+
+func (s *Sanitizer) isSensitive(name string) bool {
+	return s.fields[normalize(name)]
+}
+
+// normalize makes proto ("full_name"), json ("fullName") and Go ("FullName") names comparable
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), "_", ""))
+}
@@ -0,0 +1,15 @@
+package security
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// CompareHashAndPassword reports whether password matches the stored bcrypt hash.
+// Stored values that are not bcrypt hashes never match, such accounts need a new password.
+func CompareHashAndPassword(stored, password string) bool {
+	if stored == "" || password == "" {
+		return false
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+}
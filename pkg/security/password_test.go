@@ -0,0 +1,34 @@
+package security
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestCompareHashAndPassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("Secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		stored   string
+		password string
+		want     bool
+	}{
+		{"matching hash", string(hash), "Secret123", true},
+		{"wrong password", string(hash), "Secret124", false},
+		{"plain stored value", "Secret123", "Secret123", false},
+		{"empty password", string(hash), "", false},
+		{"empty stored value", "", "Secret123", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CompareHashAndPassword(tt.stored, tt.password); got != tt.want {
+				t.Errorf("CompareHashAndPassword() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package pkg
+
+import (
+	"time"
+
+	at "crmapi/genproto/schedule_service/attendances"
+	ls "crmapi/genproto/schedule_service/lessons"
+	sc "crmapi/genproto/schedule_service/schedules"
+	tk "crmapi/genproto/schedule_service/tasks"
+	ad "crmapi/genproto/user_service/administrators"
+	br "crmapi/genproto/user_service/branches"
+	ev "crmapi/genproto/user_service/events"
+	gr "crmapi/genproto/user_service/groups"
+	ej "crmapi/genproto/user_service/join_events"
+	mn "crmapi/genproto/user_service/managers"
+	st "crmapi/genproto/user_service/students"
+	sp "crmapi/genproto/user_service/super_admins"
+	ss "crmapi/genproto/user_service/support_teachers"
+	ts "crmapi/genproto/user_service/teachers"
+)
+
+var (
+	dateTimeLayouts = []string{time.RFC3339, time.DateTime, time.DateOnly}
+	clockLayouts    = []string{"15:04", time.TimeOnly}
+)
+
+func init() {
+	// super admins
+	superAdmin := Fields{
+		"full_name":     {Required(), FullName()},
+		"phone":         {Required(), Phone()},
+		"password":      {Required(), Password()},
+		"salary":        {Min(0)},
+		"months_worked": {Min(0)},
+		"ielts_score":   {Range(0, 9)},
+	}
+	Register(&sp.CreateSuperAdmin{}, superAdmin)
+	Register(&sp.UpdateSuperAdmin{}, withID(superAdmin))
+
+	// administrators
+	admin := Fields{
+		"full_name":     {Required(), FullName()},
+		"phone":         {Required(), Phone()},
+		"password":      {Required(), Password()},
+		"salary":        {Min(0)},
+		"months_worked": {Min(0)},
+		"ielts_score":   {Range(0, 9)},
+		"branch_id":     {Required(), UUID()},
+	}
+	Register(&ad.CreateAdminstrator{}, admin)
+	Register(&ad.UpdateAdminstrator{}, withID(admin))
+
+	// managers
+	manager := Fields{
+		"full_name":      {Required(), FullName()},
+		"phone":          {Required(), Phone()},
+		"password":       {Required(), Password()},
+		"salary":         {Min(0)},
+		"super_admin_id": {UUID()},
+		"branch_id":      {Required(), UUID()},
+	}
+	Register(&mn.CreateManager{}, manager)
+	Register(&mn.UpdateManager{}, withID(manager))
+
+	// teachers
+	teacher := Fields{
+		"full_name":           {Required(), FullName()},
+		"phone":               {Required(), Phone()},
+		"password":            {Required(), Password()},
+		"salary":              {Min(0)},
+		"months_worked":       {Min(0)},
+		"ielts_score":         {Range(0, 9)},
+		"ielts_attepms_count": {Min(0)},
+		"support_teacher_id":  {UUID()},
+		"branch_id":           {Required(), UUID()},
+		"group_id":            {UUID()},
+	}
+	Register(&ts.CreateTeacher{}, teacher)
+	Register(&ts.UpdateTeacher{}, withID(teacher))
+
+	// support teachers
+	supportTeacher := Fields{
+		"full_name":           {Required(), FullName()},
+		"phone":               {Required(), Phone()},
+		"password":            {Required(), Password()},
+		"ielts_score":         {Range(0, 9)},
+		"ielts_attepms_count": {Min(0)},
+		"salary":              {Min(0)},
+		"months_worked":       {Min(0)},
+		"branch_id":           {Required(), UUID()},
+		"group_id":            {UUID()},
+	}
+	Register(&ss.CreateSupportTeacher{}, supportTeacher)
+	Register(&ss.UpdateSupportTeacher{}, withID(supportTeacher))
+
+	// students
+	student := Fields{
+		"full_name":    {Required(), FullName()},
+		"phone":        {Required(), Phone()},
+		"password":     {Required(), Password()},
+		"paid_sum":     {Min(0)},
+		"course_count": {Min(0)},
+		"total_sum":    {Min(0)},
+		"branch_id":    {Required(), UUID()},
+		"group_id":     {UUID()},
+	}
+	Register(&st.CreateStudent{}, student)
+	Register(&st.UpdateStudent{}, withID(student, Fields{"scores": {Min(0)}}))
+
+	// branches
+	branch := Fields{
+		"name":               {Required(), Length(2, 100)},
+		"location.latitude":  {Range(-90, 90)},
+		"location.longitude": {Range(-180, 180)},
+		"super_admin_id":     {Required(), UUID()},
+	}
+	Register(&br.CreateBranch{}, branch)
+	Register(&br.UpdateBranch{}, withID(branch))
+
+	// groups
+	group := Fields{
+		"name":               {Required(), Length(1, 100)},
+		"level":              {Enum()},
+		"months":             {Range(1, 36)},
+		"number_of_students": {Min(0)},
+		"branch_id":          {Required(), UUID()},
+	}
+	Register(&gr.CreateGroup{}, group)
+	Register(&gr.UpdateGroup{}, withID(group))
+
+	// events
+	event := Fields{
+		"topic":          {Required(), Length(1, 200)},
+		"day":            {Required(), WorkingDay()},
+		"start_time":     {Required(), TimeFormat(clockLayouts...)},
+		"duration_hours": {Range(1, 24)},
+		"branch_id":      {Required(), UUID()},
+	}
+	Register(&ev.CreateEvent{}, event)
+	Register(&ev.UpdateEvent{}, withID(event))
+
+	// join events
+	Register(&ej.CreateJoinEvent{}, Fields{
+		"event_id":   {Required(), UUID()},
+		"student_id": {Required(), UUID()},
+	})
+
+	// schedules
+	schedule := Fields{
+		"group_id":           {Required(), UUID()},
+		"start_time":         {Required(), TimeFormat(append(dateTimeLayouts, clockLayouts...)...)},
+		"end_time":           {Required(), TimeFormat(append(dateTimeLayouts, clockLayouts...)...)},
+		"branch_id":          {Required(), UUID()},
+		"teacher_id":         {Required(), UUID()},
+		"support_teacher_id": {UUID()},
+	}
+	Register(&sc.CreateSchedule{}, schedule)
+	Register(&sc.UpdateSchedule{}, withID(schedule))
+
+	// lessons
+	lesson := Fields{
+		"schedule_id": {Required(), UUID()},
+	}
+	Register(&ls.CreateLesson{}, lesson)
+	Register(&ls.UpdateLesson{}, withID(lesson))
+
+	// tasks
+	task := Fields{
+		"lesson_id": {Required(), UUID()},
+		"label":     {Required(), Length(1, 255)},
+		"deadline":  {Required(), TimeFormat(dateTimeLayouts...)},
+		"score":     {Range(0, 100)},
+	}
+	Register(&tk.CreateTask{}, task)
+	Register(&tk.UpdateTask{}, withID(task))
+
+	// attendances
+	attendance := Fields{
+		"lesson_id":   {Required(), UUID()},
+		"student_id":  {Required(), UUID()},
+		"status":      {Enum()},
+		"late_minute": {Min(0)},
+	}
+	Register(&at.CreateAttendance{}, attendance)
+	Register(&at.UpdateAttendance{}, withID(attendance))
+}
+
+// withID extends create rules with the id of an update message and any extra fields
+func withID(fields Fields, extra ...Fields) Fields {
+	out := Fields{"id": {Required(), UUID()}}
+	for k, v := range fields {
+		out[k] = v
+	}
+	for _, e := range extra {
+		for k, v := range e {
+			out[k] = v
+		}
+	}
+	return out
+}
@@ -4,10 +4,16 @@ import (
 	"errors"
 	"regexp"
 	"strings"
+	"time"
+)
+
+var (
+	passwordRegex = regexp.MustCompile(`^[A-Za-z0-9$_@.#]+$`)
+	phoneRegex    = regexp.MustCompile(`^\+998\d{9}$`)
 )
 
 func ValidateFullName(name string) error {
-	if len(strings.Split(name, " ")) < 2 {
+	if len(strings.Fields(name)) < 2 {
 		return errors.New("full name's length must be 2")
 	}
 
@@ -22,9 +28,7 @@ func ValidatePassword(password string) error {
 		return errors.New("password length should be 8 to 30 characters")
 	}
 
-	_, err := regexp.MatchString(`^[A-Za-z0-9$_@.#]+$`, password)
-
-	if err != nil {
+	if !passwordRegex.MatchString(password) {
 		return errors.New("password should contain only alphabetic characters, numbers and special characters(@, $, _, ., #)")
 	}
 
@@ -32,14 +36,22 @@ func ValidatePassword(password string) error {
 }
 
 func ValidatePhone(phone string) error {
-	_, err := regexp.MatchString(`^\+998\d{9}$`, phone)
-
-	if err != nil {
+	if !phoneRegex.MatchString(phone) {
 		return errors.New("phone number must be +998")
 	}
 	return nil
 }
 
+// ValidateDay reports whether day is a working day. day is either a weekday name or a 2006-01-02 date.
 func ValidateDay(day string) bool {
-	return !strings.EqualFold(day, "sunday")
+	if t, err := time.Parse(time.DateOnly, day); err == nil {
+		return t.Weekday() != time.Sunday
+	}
+
+	for d := time.Monday; d <= time.Saturday; d++ {
+		if strings.EqualFold(day, d.String()) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,85 @@
+package pkg
+
+import "testing"
+
+func TestValidatePhone(t *testing.T) {
+	tests := []struct {
+		phone string
+		ok    bool
+	}{
+		{"+998901234567", true},
+		{"998901234567", false},
+		{"+99890123456", false},
+		{"+9989012345678", false},
+		{"+7901234567", false},
+		{"+998 90 123 45 67", false},
+		{"+99890123456a", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if err := ValidatePhone(tt.phone); (err == nil) != tt.ok {
+			t.Errorf("ValidatePhone(%q) = %v, want ok %v", tt.phone, err, tt.ok)
+		}
+	}
+}
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		password string
+		ok       bool
+	}{
+		{"Secret123", true},
+		{"s3cr$t_@.#", true},
+		{"", false},
+		{"short1", false},
+		{"aaaaaaaaaabbbbbbbbbbccccccccccd", false},
+		{"with space1", false},
+		{"semicolon;1", false},
+		{"кириллица123", false},
+	}
+	for _, tt := range tests {
+		if err := ValidatePassword(tt.password); (err == nil) != tt.ok {
+			t.Errorf("ValidatePassword(%q) = %v, want ok %v", tt.password, err, tt.ok)
+		}
+	}
+}
+
+func TestValidateFullName(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"Ali Valiyev", true},
+		{"  Ali   Valiyev ", true},
+		{"Ali", false},
+		{"Ali ", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if err := ValidateFullName(tt.name); (err == nil) != tt.ok {
+			t.Errorf("ValidateFullName(%q) = %v, want ok %v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestValidateDay(t *testing.T) {
+	tests := []struct {
+		day  string
+		want bool
+	}{
+		{"monday", true},
+		{"Saturday", true},
+		{"SUNDAY", false},
+		{"sunday", false},
+		{"2024-01-06", true}, // saturday
+		{"2024-01-07", false},
+		{"2024-13-01", false},
+		{"someday", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidateDay(tt.day); got != tt.want {
+			t.Errorf("ValidateDay(%q) = %v, want %v", tt.day, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+package pkg
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldError describes one failed rule of one request field.
+// Key and Args let callers translate Message.
+type FieldError struct {
+	Field   string        `json:"field"`
+	Rule    string        `json:"rule"`
+	Message string        `json:"message"`
+	Key     string        `json:"-"`
+	Args    []interface{} `json:"-"`
+}
+
+// ValidationErrors is returned by Validate when at least one rule fails
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(v))
+	for _, e := range v {
+		msgs = append(msgs, e.Field+": "+e.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Rule checks a single field value. Every rule except Required accepts empty values,
+// so optional fields are only checked when they are set.
+type Rule struct {
+	Name    string
+	Message string
+	key     string
+	args    []interface{}
+	check   func(v protoreflect.Value, fd protoreflect.FieldDescriptor) bool
+}
+
+// Fields maps proto field names (dotted for nested messages) to their rules
+type Fields map[string][]Rule
+
+var schemas = map[protoreflect.FullName]Fields{}
+
+// Register declares the rules of a request message. Unknown field names panic
+// so that typos in the rule tables fail at startup.
+func Register(m proto.Message, fields Fields) {
+	desc := m.ProtoReflect().Descriptor()
+	for path := range fields {
+		if _, ok := lookupField(desc, path); !ok {
+			panic(fmt.Sprintf("validator: %s has no field %q", desc.FullName(), path))
+		}
+	}
+	schemas[desc.FullName()] = fields
+}
+
+// Validate checks every registered rule of the message
+func Validate(m proto.Message) error {
+	return validate(m, nil)
+}
+
+// ValidateFields checks only the rules of the given fields, e.g. the ones changed by a partial update
+func ValidateFields(m proto.Message, fields []string) error {
+	only := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		only[f] = true
+	}
+	return validate(m, only)
+}
+
+func validate(m proto.Message, only map[string]bool) error {
+	msg := m.ProtoReflect()
+	fields, ok := schemas[msg.Descriptor().FullName()]
+	if !ok {
+		return nil
+	}
+
+	var errs ValidationErrors
+	walk(msg.Descriptor(), "", func(path string) {
+		rules, ok := fields[path]
+		if !ok || (only != nil && !only[path] && !only[strings.SplitN(path, ".", 2)[0]]) {
+			return
+		}
+
+		fd, v := valueAt(msg, path)
+		for _, r := range rules {
+			if r.Name != ruleRequired && isEmpty(v, fd) {
+				continue
+			}
+			if !r.check(v, fd) {
+				key := r.key
+				if key == "" {
+					key = r.Name
+				}
+				errs = append(errs, FieldError{Field: path, Rule: r.Name, Message: r.Message, Key: key, Args: r.args})
+				if r.Name == ruleRequired {
+					break
+				}
+			}
+		}
+	})
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// walk visits field paths in declaration order so that errors are reported deterministically
+func walk(desc protoreflect.MessageDescriptor, prefix string, visit func(path string)) {
+	for i := 0; i < desc.Fields().Len(); i++ {
+		fd := desc.Fields().Get(i)
+		path := prefix + string(fd.Name())
+		visit(path)
+		if fd.Message() != nil && !fd.IsList() && !fd.IsMap() {
+			walk(fd.Message(), path+".", visit)
+		}
+	}
+}
+
+func lookupField(desc protoreflect.MessageDescriptor, path string) (protoreflect.FieldDescriptor, bool) {
+	var fd protoreflect.FieldDescriptor
+	for _, name := range strings.Split(path, ".") {
+		if desc == nil {
+			return nil, false
+		}
+		fd = desc.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			return nil, false
+		}
+		desc = fd.Message()
+	}
+	return fd, true
+}
+
+func valueAt(msg protoreflect.Message, path string) (protoreflect.FieldDescriptor, protoreflect.Value) {
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if i == len(names)-1 {
+			return fd, msg.Get(fd)
+		}
+		if !msg.Has(fd) {
+			last, _ := lookupField(msg.Descriptor(), strings.Join(names[i:], "."))
+			return last, last.Default()
+		}
+		msg = msg.Get(fd).Message()
+	}
+	return nil, protoreflect.Value{}
+}
+
+func isEmpty(v protoreflect.Value, fd protoreflect.FieldDescriptor) bool {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return strings.TrimSpace(v.String()) == ""
+	case protoreflect.MessageKind:
+		return !v.IsValid() || !v.Message().IsValid()
+	}
+	return !v.IsValid() || v.Equal(fd.Default())
+}
+
+func toFloat(v protoreflect.Value, fd protoreflect.FieldDescriptor) float64 {
+	switch fd.Kind() {
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float()
+	case protoreflect.Int32Kind, protoreflect.Int64Kind, protoreflect.Sint32Kind, protoreflect.Sint64Kind,
+		protoreflect.Sfixed32Kind, protoreflect.Sfixed64Kind:
+		return float64(v.Int())
+	case protoreflect.Uint32Kind, protoreflect.Uint64Kind, protoreflect.Fixed32Kind, protoreflect.Fixed64Kind:
+		return float64(v.Uint())
+	case protoreflect.EnumKind:
+		return float64(v.Enum())
+	}
+	return 0
+}
+
+const ruleRequired = "required"
+
+// Required ...
+func Required() Rule {
+	return Rule{Name: ruleRequired, Message: "is required", check: func(v protoreflect.Value, fd protoreflect.FieldDescriptor) bool {
+		return !isEmpty(v, fd)
+	}}
+}
+
+// Regex ...
+func Regex(pattern, message string) Rule {
+	re := regexp.MustCompile(pattern)
+	return Rule{Name: "regex", Message: message, check: func(v protoreflect.Value, _ protoreflect.FieldDescriptor) bool {
+		return re.MatchString(v.String())
+	}}
+}
+
+// Min ...
+func Min(min float64) Rule {
+	return Rule{Name: "min", Message: fmt.Sprintf("must be at least %v", min), args: []interface{}{min}, check: func(v protoreflect.Value, fd protoreflect.FieldDescriptor) bool {
+		return toFloat(v, fd) >= min
+	}}
+}
+
+// Max ...
+func Max(max float64) Rule {
+	return Rule{Name: "max", Message: fmt.Sprintf("must be at most %v", max), args: []interface{}{max}, check: func(v protoreflect.Value, fd protoreflect.FieldDescriptor) bool {
+		return toFloat(v, fd) <= max
+	}}
+}
+
+// Range ...
+func Range(min, max float64) Rule {
+	return Rule{Name: "range", Message: fmt.Sprintf("must be between %v and %v", min, max), args: []interface{}{min, max}, check: func(v protoreflect.Value, fd protoreflect.FieldDescriptor) bool {
+		f := toFloat(v, fd)
+		return f >= min && f <= max
+	}}
+}
+
+// Length limits the number of characters of a string
+func Length(min, max int) Rule {
+	return Rule{Name: "length", Message: fmt.Sprintf("length must be %d to %d characters", min, max), args: []interface{}{min, max}, check: func(v protoreflect.Value, _ protoreflect.FieldDescriptor) bool {
+		n := len([]rune(v.String()))
+		return n >= min && n <= max
+	}}
+}
+
+// UUID ...
+func UUID() Rule {
+	return Rule{Name: "uuid", Message: "must be a valid uuid", check: func(v protoreflect.Value, _ protoreflect.FieldDescriptor) bool {
+		return uuid.Validate(v.String()) == nil
+	}}
+}
+
+// Enum accepts the listed strings, or for proto enum fields any declared value when none are listed
+func Enum(values ...string) Rule {
+	msg, key, args := "must be a known value", "enum", []interface{}(nil)
+	if len(values) > 0 {
+		msg, key, args = "must be one of "+strings.Join(values, ", "), "enum_values", []interface{}{strings.Join(values, ", ")}
+	}
+	return Rule{Name: "enum", Message: msg, key: key, args: args, check: func(v protoreflect.Value, fd protoreflect.FieldDescriptor) bool {
+		if fd.Kind() == protoreflect.EnumKind {
+			return fd.Enum().Values().ByNumber(v.Enum()) != nil
+		}
+		for _, val := range values {
+			if strings.EqualFold(v.String(), val) {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// TimeFormat accepts values that parse with any of the layouts
+func TimeFormat(layouts ...string) Rule {
+	return Rule{Name: "time", Message: "must be a time in format " + strings.Join(layouts, " or "), args: []interface{}{strings.Join(layouts, " / ")}, check: func(v protoreflect.Value, _ protoreflect.FieldDescriptor) bool {
+		for _, layout := range layouts {
+			if _, err := time.Parse(layout, v.String()); err == nil {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// FullName ...
+func FullName() Rule {
+	return fromFunc("full_name", ValidateFullName, "must contain first and last name")
+}
+
+// Phone ...
+func Phone() Rule {
+	return fromFunc("phone", ValidatePhone, "must be in format +998XXXXXXXXX")
+}
+
+// Password ...
+func Password() Rule {
+	return fromFunc("password", ValidatePassword, "must be 8 to 30 characters of letters, digits and @ $ _ . #")
+}
+
+// WorkingDay accepts weekday names and dates except sundays
+func WorkingDay() Rule {
+	return Rule{Name: "working_day", Message: "must be a weekday name or a 2006-01-02 date, not sunday", check: func(v protoreflect.Value, _ protoreflect.FieldDescriptor) bool {
+		return ValidateDay(v.String())
+	}}
+}
+
+func fromFunc(name string, fn func(string) error, message string) Rule {
+	return Rule{Name: name, Message: message, check: func(v protoreflect.Value, _ protoreflect.FieldDescriptor) bool {
+		return fn(v.String()) == nil
+	}}
+}
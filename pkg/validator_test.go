@@ -0,0 +1,161 @@
+package pkg
+
+import (
+	"reflect"
+	"testing"
+
+	at "crmapi/genproto/schedule_service/attendances"
+	tk "crmapi/genproto/schedule_service/tasks"
+	br "crmapi/genproto/user_service/branches"
+	ev "crmapi/genproto/user_service/events"
+	gr "crmapi/genproto/user_service/groups"
+	st "crmapi/genproto/user_service/students"
+
+	"google.golang.org/protobuf/proto"
+)
+
+const testUUID = "9f1c2b4e-6a7d-4c1e-8f3a-2b5d7e9c1a3f"
+
+// accepts runs a single rule against a field of m
+func accepts(r Rule, m proto.Message, field string) bool {
+	fd, v := valueAt(m.ProtoReflect(), field)
+	return r.check(v, fd)
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  Rule
+		msg   proto.Message
+		field string
+		want  bool
+	}{
+		{"uuid", UUID(), &st.CreateStudent{BranchId: testUUID}, "branch_id", true},
+		{"uuid without dashes", UUID(), &st.CreateStudent{BranchId: "9f1c2b4e6a7d4c1e8f3a2b5d7e9c1a3f"}, "branch_id", true},
+		{"uuid too short", UUID(), &st.CreateStudent{BranchId: "9f1c2b4e-6a7d"}, "branch_id", false},
+		{"uuid text", UUID(), &st.CreateStudent{BranchId: "branch-1"}, "branch_id", false},
+
+		{"enum declared value", Enum(), &gr.CreateGroup{Level: gr.CourseType_intermediate}, "level", true},
+		{"enum unknown number", Enum(), &gr.CreateGroup{Level: 42}, "level", false},
+		{"enum listed string", Enum("monday", "friday"), &ev.CreateEvent{Day: "Friday"}, "day", true},
+		{"enum unlisted string", Enum("monday", "friday"), &ev.CreateEvent{Day: "sunday"}, "day", false},
+
+		{"range inside", Range(0, 100), &tk.CreateTask{Score: 100}, "score", true},
+		{"range below", Range(0, 100), &tk.CreateTask{Score: -0.5}, "score", false},
+		{"range above", Range(0, 100), &tk.CreateTask{Score: 100.5}, "score", false},
+		{"range of a double", Range(-90, 90), &br.Location{Latitude: -91}, "latitude", false},
+		{"min", Min(0), &st.CreateStudent{PaidSum: 0}, "paid_sum", true},
+		{"min below", Min(0), &st.CreateStudent{PaidSum: -1}, "paid_sum", false},
+		{"max", Max(36), &gr.CreateGroup{Months: 37}, "months", false},
+
+		{"length", Length(2, 5), &br.CreateBranch{Name: "Chilonzor"}, "name", false},
+		{"length counts runes", Length(2, 5), &br.CreateBranch{Name: "Юнус"}, "name", true},
+
+		{"time clock", TimeFormat("15:04", "15:04:05"), &ev.CreateEvent{StartTime: "09:30"}, "start_time", true},
+		{"time other layout", TimeFormat("15:04", "15:04:05"), &ev.CreateEvent{StartTime: "09:30:15"}, "start_time", true},
+		{"time invalid", TimeFormat("15:04"), &ev.CreateEvent{StartTime: "25:00"}, "start_time", false},
+
+		{"regex", Regex(`^[a-z]+$`, "must be lowercase"), &ev.CreateEvent{Topic: "grammar"}, "topic", true},
+		{"regex mismatch", Regex(`^[a-z]+$`, "must be lowercase"), &ev.CreateEvent{Topic: "Grammar"}, "topic", false},
+		{"phone", Phone(), &st.CreateStudent{Phone: "+998901234567"}, "phone", true},
+		{"phone mismatch", Phone(), &st.CreateStudent{Phone: "+998-90-123"}, "phone", false},
+		{"password mismatch", Password(), &st.CreateStudent{Password: "pass word!"}, "password", false},
+		{"working day", WorkingDay(), &ev.CreateEvent{Day: "sunday"}, "day", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := accepts(tt.rule, tt.msg, tt.field); got != tt.want {
+				t.Errorf("%s on %s = %v, want %v", tt.rule.Name, tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	validStudent := func() *st.CreateStudent {
+		return &st.CreateStudent{FullName: "Ali Valiyev", Phone: "+998901234567", Password: "Secret123", BranchId: testUUID}
+	}
+
+	tests := []struct {
+		name string
+		msg  proto.Message
+		want ValidationErrors
+	}{
+		{"valid student", validStudent(), nil},
+		{"student", &st.CreateStudent{FullName: "Ali", Phone: "901234567", PaidSum: -5, BranchId: "b1"}, ValidationErrors{
+			{Field: "full_name", Rule: "full_name", Message: "must contain first and last name", Key: "full_name"},
+			{Field: "phone", Rule: "phone", Message: "must be in format +998XXXXXXXXX", Key: "phone"},
+			{Field: "password", Rule: "required", Message: "is required", Key: "required"},
+			{Field: "paid_sum", Rule: "min", Message: "must be at least 0", Key: "min", Args: []interface{}{0.0}},
+			{Field: "branch_id", Rule: "uuid", Message: "must be a valid uuid", Key: "uuid"},
+		}},
+		{"nested field", &br.CreateBranch{Name: "Chilonzor", SuperAdminId: testUUID, Location: &br.Location{Latitude: 95}}, ValidationErrors{
+			{Field: "location.latitude", Rule: "range", Message: "must be between -90 and 90", Key: "range", Args: []interface{}{-90.0, 90.0}},
+		}},
+		{"enum", &at.CreateAttendance{LessonId: testUUID, StudentId: testUUID, Status: 7}, ValidationErrors{
+			{Field: "status", Rule: "enum", Message: "must be a known value", Key: "enum"},
+		}},
+		{"sunday event", &ev.CreateEvent{Topic: "Speaking club", Day: "Sunday", StartTime: "10:00", BranchId: testUUID}, ValidationErrors{
+			{Field: "day", Rule: "working_day", Message: "must be a weekday name or a 2006-01-02 date, not sunday", Key: "working_day"},
+		}},
+		{"unregistered message", &st.StudentPrimaryKey{Id: "x"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.msg)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			got, ok := err.(ValidationErrors)
+			if !ok {
+				t.Fatalf("Validate() = %v, want ValidationErrors", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() =\n%#v\nwant\n%#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	update := &st.UpdateStudent{Id: testUUID, Phone: "12345", BranchId: "b1"}
+
+	err := ValidateFields(update, []string{"phone"})
+	errs, ok := err.(ValidationErrors)
+	if !ok || len(errs) != 1 || errs[0].Field != "phone" {
+		t.Errorf("ValidateFields(phone) = %v, want only the phone error", err)
+	}
+
+	if err := ValidateFields(update, []string{"id", "group_id"}); err != nil {
+		t.Errorf("ValidateFields(id, group_id) = %v, want nil", err)
+	}
+
+	branch := &br.UpdateBranch{Id: testUUID, Location: &br.Location{Longitude: 200}}
+	errs, _ = ValidateFields(branch, []string{"location"}).(ValidationErrors)
+	if len(errs) != 1 || errs[0].Field != "location.longitude" {
+		t.Errorf("ValidateFields(location) = %v, want the location.longitude error", errs)
+	}
+}
+
+func TestValidationErrorsMessage(t *testing.T) {
+	errs := ValidationErrors{
+		{Field: "phone", Message: "must be in format +998XXXXXXXXX"},
+		{Field: "branch_id", Message: "is required"},
+	}
+	want := "phone: must be in format +998XXXXXXXXX; branch_id: is required"
+	if errs.Error() != want {
+		t.Errorf("Error() = %q, want %q", errs.Error(), want)
+	}
+}
+
+func TestRegisterUnknownField(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() accepted a field the message does not have")
+		}
+	}()
+	Register(&st.StudentPrimaryKey{}, Fields{"phone": {Required()}})
+}